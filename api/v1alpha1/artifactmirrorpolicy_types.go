@@ -0,0 +1,86 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArtifactMirrorPolicySpec defines the URL rewrites of an ArtifactMirrorPolicy.
+type ArtifactMirrorPolicySpec struct {
+	// rules rewrite BootArtifact URLs that start with a rule's from prefix.
+	// Across all ArtifactMirrorPolicies the rule with the longest matching
+	// prefix applies; ties go to the policy that sorts first by name, then to
+	// the earlier rule. A URL is rewritten at most once.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=64
+	Rules []URLRewriteRule `json:"rules"`
+}
+
+// URLRewriteRule replaces a URL prefix.
+type URLRewriteRule struct {
+	// from is the upstream URL prefix, e.g. https://deb.debian.org/debian/.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=2048
+	// +kubebuilder:validation:Pattern="^(https|oci|http|file)://"
+	// +kubebuilder:validation:XValidation:rule="!self.matches('^[^?#]*/[.][.]([/?#]|$)')",message="from must not contain path traversal"
+	From string `json:"from"`
+
+	// to replaces from, e.g. https://mirror.site-a.internal/debian/. A
+	// rewritten http:// or file:// URL is only downloaded when the
+	// BootArtifact sets allowInsecureSource.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=2048
+	// +kubebuilder:validation:Pattern="^(https|oci|http|file)://"
+	// +kubebuilder:validation:XValidation:rule="!self.matches('^[^?#]*/[.][.]([/?#]|$)')",message="to must not contain path traversal"
+	To string `json:"to"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=amp
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// ArtifactMirrorPolicy is the Schema for the artifactmirrorpolicies API. It
+// rewrites the URLs BootArtifacts download from, so that the same manifests
+// can be applied at sites that each have their own mirror.
+type ArtifactMirrorPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the URL rewrites
+	// +required
+	Spec ArtifactMirrorPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ArtifactMirrorPolicyList contains a list of ArtifactMirrorPolicy
+type ArtifactMirrorPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ArtifactMirrorPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ArtifactMirrorPolicy{}, &ArtifactMirrorPolicyList{})
+}
@@ -25,40 +25,235 @@ import (
 
 // BootArtifactSpec defines the desired state of BootArtifact.
 // A BootArtifact represents a single downloadable file (kernel, initrd, or firmware)
-// with integrity verification via SHA-256 or SHA-512.
-// +kubebuilder:validation:XValidation:rule="has(self.sha256) || has(self.sha512)",message="one of sha256 or sha512 is required"
-// +kubebuilder:validation:XValidation:rule="!(has(self.sha256) && has(self.sha512))",message="sha256 and sha512 are mutually exclusive"
+// with integrity verification via SHA-256 or SHA-512, either given inline,
+// resolved from a signed upstream checksum manifest, or taken from the layer
+// digest of an OCI artifact.
+// +kubebuilder:validation:XValidation:rule="has(self.sha256) || has(self.sha512) || has(self.checksums) || self.url.startsWith('oci://')",message="one of sha256, sha512 or checksums is required for https urls"
+// +kubebuilder:validation:XValidation:rule="[has(self.sha256), has(self.sha512), has(self.checksums)].filter(x, x).size() <= 1",message="sha256, sha512 and checksums are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="(has(self.allowInsecureSource) && self.allowInsecureSource) || ([self.url] + (has(self.mirrors) ? self.mirrors : [])).all(u, u.startsWith('https://') || u.startsWith('oci://'))",message="http:// and file:// sources require allowInsecureSource"
+// +kubebuilder:validation:XValidation:rule="!has(self.allowInsecureSource) || !self.allowInsecureSource || has(self.sha256) || has(self.sha512)",message="allowInsecureSource requires a pinned sha256 or sha512"
 type BootArtifactSpec struct {
-	// url is the download URL for the artifact. Must use HTTPS.
+	// url is the download URL for the artifact. It is either an HTTPS URL or
+	// an OCI reference of the form oci://<registry>/<repository>:<tag> or
+	// oci://<registry>/<repository>@<digest>, which must name a single-layer
+	// artifact. For OCI references the file is named after the last element
+	// of the repository, e.g. oci://registry.example.com/debian/vmlinuz:13
+	// is stored as "vmlinuz". With allowInsecureSource it may also be a
+	// plain http:// URL or a file:// URL.
 	// +required
-	// +kubebuilder:validation:Pattern="^https://"
-	// +kubebuilder:validation:XValidation:rule="!self.contains('/..')",message="url must not contain path traversal"
+	// +kubebuilder:validation:Pattern="^(https|oci|http|file)://"
+	// +kubebuilder:validation:XValidation:rule="!self.matches('^[^?#]*/[.][.]([/?#]|$)')",message="url must not contain path traversal"
 	URL string `json:"url"`
 
+	// mirrors is an ordered list of alternate download URLs for the same file.
+	// They are tried in turn when url (or an earlier mirror) fails, and every
+	// mirror must serve content matching the configured digest. Each is an
+	// HTTPS URL or an OCI reference, or with allowInsecureSource an http://
+	// or file:// URL, as for url.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MaxLength=2048
+	// +kubebuilder:validation:items:Pattern="^(https|oci|http|file)://"
+	// +kubebuilder:validation:XValidation:rule="self.all(m, !m.matches('^[^?#]*/[.][.]([/?#]|$)'))",message="mirrors must not contain path traversal"
+	Mirrors []string `json:"mirrors,omitempty"`
+
 	// sha256 is the expected SHA-256 hex digest of the downloaded file.
-	// Exactly one of sha256 or sha512 must be specified.
+	// Exactly one of sha256, sha512 or checksums must be specified.
 	// +optional
 	// +kubebuilder:validation:Pattern="^[a-fA-F0-9]{64}$"
 	SHA256 *string `json:"sha256,omitempty"`
 
 	// sha512 is the expected SHA-512 hex digest of the downloaded file.
-	// Exactly one of sha256 or sha512 must be specified.
+	// Exactly one of sha256, sha512 or checksums must be specified.
 	// +optional
 	// +kubebuilder:validation:Pattern="^[a-fA-F0-9]{128}$"
 	SHA512 *string `json:"sha512,omitempty"`
+
+	// checksums resolves the expected digest from a signed upstream checksum
+	// manifest instead of an inline sha256 or sha512.
+	// Exactly one of sha256, sha512 or checksums must be specified.
+	// +optional
+	Checksums *BootArtifactChecksums `json:"checksums,omitempty"`
+
+	// imagePullSecret names a Secret of type kubernetes.io/dockerconfigjson
+	// (or kubernetes.io/dockercfg) in the same namespace holding credentials
+	// for OCI registries. Registries without an entry are accessed
+	// anonymously.
+	// +optional
+	ImagePullSecret *SecretReference `json:"imagePullSecret,omitempty"`
+
+	// auth configures credentials and trusted CAs for HTTPS sources that are
+	// not publicly accessible.
+	// +optional
+	Auth *BootArtifactAuth `json:"auth,omitempty"`
+
+	// allowInsecureSource permits plain http:// URLs and file:// URLs in url
+	// and mirrors. Integrity then rests on the digest alone, so sha256 or
+	// sha512 must be set. file:// URLs name a path under the controller's
+	// --file-source-root, such as a mounted volume; spec.auth credentials
+	// are never sent over plain HTTP.
+	// +optional
+	AllowInsecureSource bool `json:"allowInsecureSource,omitempty"`
+
+	// priority orders this artifact in the controller's download queue when
+	// the concurrent download limit is reached. Higher values start first;
+	// artifacts with equal priority start in the order they were queued.
+	// +optional
+	// +kubebuilder:validation:Minimum=-1000
+	// +kubebuilder:validation:Maximum=1000
+	Priority int32 `json:"priority,omitempty"`
+
+	// decompress names the compression format of the downloaded file. The
+	// configured digest still applies to the compressed download; once it is
+	// verified the file is decompressed and the output is served instead,
+	// under the filename of url without its ".gz", ".xz" or ".zst"
+	// extension (e.g. vmlinuz.xz is served as "vmlinuz"). The digest of the
+	// output is recorded in status.decompressedDigest.
+	// +optional
+	Decompress BootArtifactCompression `json:"decompress,omitempty"`
+
+	// driftCheckInterval enables a periodic check of the source the file on
+	// disk was downloaded from: the source's headers are fetched with a HEAD
+	// request, or a conditional GET where HEAD is not supported, and an
+	// ETag, Last-Modified or size that differs from status.provenance sets
+	// the UpstreamChanged condition. The verified file on disk is left as it
+	// is. oci:// sources are not checked.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="driftCheckInterval must be at least 1m"
+	DriftCheckInterval *metav1.Duration `json:"driftCheckInterval,omitempty"`
+}
+
+// BootArtifactCompression is a compression format for spec.decompress.
+// +kubebuilder:validation:Enum=gzip;xz;zstd
+type BootArtifactCompression string
+
+const (
+	BootArtifactCompressionGzip BootArtifactCompression = "gzip"
+	BootArtifactCompressionXZ   BootArtifactCompression = "xz"
+	BootArtifactCompressionZstd BootArtifactCompression = "zstd"
+)
+
+// BootArtifactChecksums points at a distro checksum manifest (such as
+// SHA256SUMS or CHECKSUM) and its detached OpenPGP signature. The controller
+// verifies the signature against the keyring and looks up the artifact's
+// digest in the manifest. Both GNU ("<hex>  <file>") and BSD
+// ("SHA256 (<file>) = <hex>") manifest lines are understood.
+type BootArtifactChecksums struct {
+	// url is the download URL of the checksum manifest. Must use HTTPS.
+	// +required
+	// +kubebuilder:validation:Pattern="^https://"
+	// +kubebuilder:validation:XValidation:rule="!self.matches('^[^?#]*/[.][.]([/?#]|$)')",message="url must not contain path traversal"
+	URL string `json:"url"`
+
+	// signatureURL is the download URL of the detached OpenPGP signature over
+	// the manifest (armored or binary, e.g. SHA256SUMS.gpg). Must use HTTPS.
+	// +required
+	// +kubebuilder:validation:Pattern="^https://"
+	// +kubebuilder:validation:XValidation:rule="!self.matches('^[^?#]*/[.][.]([/?#]|$)')",message="signatureURL must not contain path traversal"
+	SignatureURL string `json:"signatureURL"`
+
+	// keyring selects the ConfigMap key holding the OpenPGP public keys
+	// trusted to sign the manifest.
+	// +required
+	Keyring ConfigMapKeyReference `json:"keyring"`
+
+	// filename is the manifest entry to look up, e.g. "netboot/amd64/linux".
+	// When omitted, the entry whose last path element matches the filename
+	// of url is used.
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	Filename string `json:"filename,omitempty"`
+}
+
+// ConfigMapKeyReference selects a key of a ConfigMap in the same namespace.
+type ConfigMapKeyReference struct {
+	// name is the name of the ConfigMap.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// key is the data key within the ConfigMap.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// BootArtifactAuth configures access to private HTTPS download sources. It
+// applies to url, mirrors and the checksums URLs; credentials are only sent
+// to the hosts named there, never to hosts reached through a redirect. For
+// oci:// sources only the TLS settings apply; registry credentials come from
+// imagePullSecret.
+type BootArtifactAuth struct {
+	// secretRef names a Secret in the same namespace holding credentials.
+	// Recognised keys are "token" (sent as a bearer token), "username" and
+	// "password" (HTTP basic auth), and "tls.crt" and "tls.key" (a client
+	// certificate for mutual TLS). "token" and "username" are mutually
+	// exclusive; a kubernetes.io/tls Secret works as-is for client
+	// certificates.
+	// +optional
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+
+	// caBundle selects a ConfigMap key holding PEM-encoded CA certificates
+	// trusted in addition to the system roots.
+	// +optional
+	CABundle *ConfigMapKeyReference `json:"caBundle,omitempty"`
+}
+
+// SecretReference names a Secret in the same namespace.
+type SecretReference struct {
+	// name is the name of the Secret.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// SecretKeyReference selects a key of a Secret in the same namespace.
+type SecretKeyReference struct {
+	// name is the name of the Secret.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// key is the data key within the Secret.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
 }
 
 // BootArtifactPhase describes the current phase of a BootArtifact.
-// +kubebuilder:validation:Enum=Pending;Downloading;Ready;Error
+// +kubebuilder:validation:Enum=Pending;Queued;Downloading;Ready;Error;Evicted
 type BootArtifactPhase string
 
 const (
 	BootArtifactPhasePending     BootArtifactPhase = "Pending"
+	BootArtifactPhaseQueued      BootArtifactPhase = "Queued"
 	BootArtifactPhaseDownloading BootArtifactPhase = "Downloading"
 	BootArtifactPhaseReady       BootArtifactPhase = "Ready"
 	BootArtifactPhaseError       BootArtifactPhase = "Error"
+	// BootArtifactPhaseEvicted means the file was removed to free disk space
+	// because no BootConfig referenced it. It is downloaded again once one
+	// does.
+	BootArtifactPhaseEvicted BootArtifactPhase = "Evicted"
 )
 
+// BootArtifactConditionDiskPressure is True while this artifact's download
+// is held back because the data directory does not have enough free space
+// for it. The data directory's own state is reported by the controller's
+// readiness probe and metrics.
+const BootArtifactConditionDiskPressure = "DiskPressure"
+
+// BootArtifactConditionUpstreamChanged is True when a drift check found that
+// the source now serves a file with a different ETag, Last-Modified or size
+// than the one downloaded. It is cleared by the next download.
+const BootArtifactConditionUpstreamChanged = "UpstreamChanged"
+
+// BootArtifactAnnotationImportedDigests carries, as a JSON object with
+// resolvedDigest and decompressedDigest, the status digests of a
+// BootArtifact created by a bundle import. The controller records them in
+// status when it first reconciles the artifact, before resolving anything
+// over the network, and then removes the annotation.
+const BootArtifactAnnotationImportedDigests = "isoboot.github.io/imported-digests"
+
 // BootArtifactStatus defines the observed state of BootArtifact.
 type BootArtifactStatus struct {
 	// phase is the current phase of the artifact.
@@ -80,11 +275,137 @@ type BootArtifactStatus struct {
 	// lastChecked is the last time the artifact file was verified.
 	// +optional
 	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
+
+	// sourceURL is the URL (spec.url or one of spec.mirrors) that the file on
+	// disk was downloaded from.
+	// +optional
+	SourceURL string `json:"sourceURL,omitempty"`
+
+	// effectiveURL is the URL sourceURL was downloaded from after an
+	// ArtifactMirrorPolicy rewrote it; empty when no rule applied.
+	// +optional
+	EffectiveURL string `json:"effectiveURL,omitempty"`
+
+	// provenance describes the download that produced the file on disk. It
+	// is unset when the file was linked from another BootArtifact's blob
+	// rather than downloaded.
+	// +optional
+	Provenance *BootArtifactProvenance `json:"provenance,omitempty"`
+
+	// lastDriftCheck is the last time the source was checked for changes
+	// against provenance, when spec.driftCheckInterval is set.
+	// +optional
+	LastDriftCheck *metav1.Time `json:"lastDriftCheck,omitempty"`
+
+	// observedGeneration is the metadata.generation whose spec the file on
+	// disk was last verified against.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// progress reports the transfer while phase is Downloading. It is
+	// refreshed periodically rather than continuously and cleared once the
+	// download finishes or fails.
+	// +optional
+	Progress *BootArtifactProgress `json:"progress,omitempty"`
+
+	// resolvedDigest is the digest obtained from spec.checksums or from the
+	// layer of an OCI artifact, in "<algorithm>:<hex>" form
+	// (e.g. "sha256:3f2a..."). It is reused to verify the file on disk until
+	// the next download.
+	// +optional
+	ResolvedDigest string `json:"resolvedDigest,omitempty"`
+
+	// decompressedDigest is the SHA-256 digest of the served file when
+	// spec.decompress is set, in "sha256:<hex>" form. It is used to verify
+	// the file on disk until the next download.
+	// +optional
+	DecompressedDigest string `json:"decompressedDigest,omitempty"`
+
+	// fileSpecHash is a hash of the spec fields that define the file: url,
+	// sha256, sha512, checksums and decompress. resolvedDigest and
+	// decompressedDigest are discarded when it no longer matches the spec.
+	// +optional
+	FileSpecHash string `json:"fileSpecHash,omitempty"`
+
+	// conditions represent the latest available observations of the
+	// artifact's state: Ready, Progressing, Degraded, DiskPressure and
+	// UpstreamChanged.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// BootArtifactProgress describes an in-flight download.
+type BootArtifactProgress struct {
+	// receivedBytes is the number of bytes of the file on disk so far,
+	// including any partial download that was resumed.
+	// +required
+	ReceivedBytes int64 `json:"receivedBytes"`
+
+	// totalBytes is the expected file size from Content-Length, or unset if
+	// the server did not send one.
+	// +optional
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+
+	// percent is receivedBytes as a percentage of totalBytes.
+	// +optional
+	Percent int32 `json:"percent,omitempty"`
+
+	// bytesPerSecond is the transfer rate since the previous progress update.
+	// +optional
+	BytesPerSecond int64 `json:"bytesPerSecond,omitempty"`
+
+	// eta is the estimated time remaining at the current rate.
+	// +optional
+	ETA *metav1.Duration `json:"eta,omitempty"`
+}
+
+// BootArtifactProvenance records where and when a file was downloaded, so a
+// later download of the same source shows whether the upstream moved or
+// republished it.
+type BootArtifactProvenance struct {
+	// finalURL is the URL the file was served from after redirects, without
+	// its query string, which for registry and object store redirects
+	// carries short-lived signatures.
+	// +optional
+	FinalURL string `json:"finalURL,omitempty"`
+
+	// etag is the ETag response header.
+	// +optional
+	ETag string `json:"etag,omitempty"`
+
+	// lastModified is the Last-Modified response header.
+	// +optional
+	LastModified string `json:"lastModified,omitempty"`
+
+	// contentLength is the size of the download in bytes, including any
+	// partial download that was resumed.
+	// +optional
+	ContentLength int64 `json:"contentLength,omitempty"`
+
+	// startTime is when the request that completed the download was sent.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// completionTime is when the download was verified and stored.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// digest is the digest computed over the downloaded bytes, in
+	// "<algorithm>:<hex>" form, before any spec.decompress.
+	// +optional
+	Digest string `json:"digest,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Percent",type=integer,JSONPath=".status.progress.percent"
+// +kubebuilder:printcolumn:name="ETA",type=string,JSONPath=".status.progress.eta"
+// +kubebuilder:printcolumn:name="Received",type=integer,JSONPath=".status.progress.receivedBytes",priority=1
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=".status.progress.totalBytes",priority=1
+// +kubebuilder:printcolumn:name="Rate",type=integer,JSONPath=".status.progress.bytesPerSecond",priority=1
 // +kubebuilder:printcolumn:name="Failures",type=integer,JSONPath=".status.failureCount"
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
 
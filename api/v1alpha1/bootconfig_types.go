@@ -17,6 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"path"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,15 +30,184 @@ type BootConfigISOSpec struct {
 	// +kubebuilder:validation:MinLength=1
 	ArtifactRef string `json:"artifactRef"`
 
-	// kernelPath is the path to the kernel within the ISO.
+	// kernelPath is the path to the kernel within the ISO. When omitted it
+	// is detected from the ISO's .treeinfo, boot loader configuration or
+	// casper/ directory; status.iso shows what was found.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	KernelPath string `json:"kernelPath,omitempty"`
+
+	// initrdPath is the path to the initrd within the ISO. When omitted it
+	// is detected like kernelPath.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	InitrdPath string `json:"initrdPath,omitempty"`
+
+	// extraFiles are further files extracted from the ISO into the boot
+	// directory alongside the kernel and initrd, such as an installer stage
+	// image or .treeinfo, so installers need not fetch the whole ISO. Their
+	// URLs are available to kernelArgs as {{index .Files "<as>"}}.
+	// +optional
+	// +kubebuilder:validation:MaxItems=32
+	ExtraFiles []BootConfigISOFile `json:"extraFiles,omitempty"`
+}
+
+// BootConfigISOFile names a file to extract from an ISO, archive or disk
+// image.
+type BootConfigISOFile struct {
+	// path is the path to the file within the ISO, archive or disk image
+	// partition.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	Path string `json:"path"`
+
+	// as is the relative path the file is served under in the boot
+	// directory, e.g. "images/install.img". It defaults to path, so the
+	// source's layout is kept. It may not be "vmlinuz", "initrd", the ISO's
+	// filename or under "overlaid/".
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	As string `json:"as,omitempty"`
+}
+
+// Name returns the path, relative to the boot directory, that the file is
+// served under: as when given, otherwise its path within the source.
+func (f BootConfigISOFile) Name() string {
+	if f.As != "" {
+		return f.As
+	}
+	return strings.TrimPrefix(f.Path, "/")
+}
+
+// BootConfigArchiveFormat is the format of a spec.archive artifact.
+// +kubebuilder:validation:Enum=tar;tar.gz;zip
+type BootConfigArchiveFormat string
+
+const (
+	BootConfigArchiveFormatTar   BootConfigArchiveFormat = "tar"
+	BootConfigArchiveFormatTarGz BootConfigArchiveFormat = "tar.gz"
+	BootConfigArchiveFormatZip   BootConfigArchiveFormat = "zip"
+)
+
+// BootConfigArchiveSpec defines the archive extraction configuration, for
+// netboot tarballs such as Debian's netboot.tar.gz.
+type BootConfigArchiveSpec struct {
+	// artifactRef is the name of the BootArtifact for the archive.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	ArtifactRef string `json:"artifactRef"`
+
+	// format is the archive format. When omitted it is inferred from the
+	// artifact's filename: ".tar", ".tar.gz" or ".tgz", or ".zip".
+	// +optional
+	Format BootConfigArchiveFormat `json:"format,omitempty"`
+
+	// kernelPath is the path of the kernel within the archive, e.g.
+	// "debian-installer/amd64/linux".
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	KernelPath string `json:"kernelPath"`
+
+	// initrdPath is the path of the initrd within the archive, e.g.
+	// "debian-installer/amd64/initrd.gz".
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	InitrdPath string `json:"initrdPath"`
+
+	// extraFiles are further archive members extracted into the boot
+	// directory alongside the kernel and initrd. Their URLs are available to
+	// kernelArgs as {{index .Files "<as>"}}.
+	// +optional
+	// +kubebuilder:validation:MaxItems=32
+	ExtraFiles []BootConfigISOFile `json:"extraFiles,omitempty"`
+}
+
+// BootConfigDiskImageSpec defines the disk image extraction configuration,
+// for vendors that ship only raw or GPT disk images, such as Flatcar. The
+// boot files are read from a FAT, ext4, ISO9660 or squashfs filesystem.
+// +kubebuilder:validation:XValidation:rule="has(self.partition) != has(self.partitionLabel)",message="must set exactly one of partition or partitionLabel"
+type BootConfigDiskImageSpec struct {
+	// artifactRef is the name of the BootArtifact for the disk image.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	ArtifactRef string `json:"artifactRef"`
+
+	// partition is the 1-based index of the partition holding the boot
+	// files, or 0 for an image that is a single filesystem without a
+	// partition table.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=128
+	Partition *int32 `json:"partition,omitempty"`
+
+	// partitionLabel selects the partition holding the boot files by its
+	// GPT partition name, such as "EFI-SYSTEM", or else by its filesystem
+	// label.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=72
+	PartitionLabel string `json:"partitionLabel,omitempty"`
+
+	// kernelPath is the path of the kernel within the partition, e.g.
+	// "flatcar/vmlinuz-a".
 	// +required
 	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
 	KernelPath string `json:"kernelPath"`
 
-	// initrdPath is the path to the initrd within the ISO.
+	// initrdPath is the path of the initrd within the partition.
 	// +required
 	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
 	InitrdPath string `json:"initrdPath"`
+
+	// extraFiles are further files extracted from the partition into the
+	// boot directory alongside the kernel and initrd. Their URLs are
+	// available to kernelArgs as {{index .Files "<as>"}}.
+	// +optional
+	// +kubebuilder:validation:MaxItems=32
+	ExtraFiles []BootConfigISOFile `json:"extraFiles,omitempty"`
+}
+
+// BootConfigInitrdOverlay is a file added to the initrd overlay, a
+// gzip-compressed newc cpio archive the controller appends to the served
+// initrd, e.g. a kickstart file, CA certificate or driver update disk. Its
+// contents come from exactly one of configMapKeyRef, secretKeyRef or
+// artifactRef.
+// +kubebuilder:validation:XValidation:rule="[has(self.configMapKeyRef), has(self.secretKeyRef), has(self.artifactRef)].filter(s, s).size() == 1",message="must set exactly one of configMapKeyRef, secretKeyRef or artifactRef"
+type BootConfigInitrdOverlay struct {
+	// path is where the file is placed in the initramfs, e.g. "/ks.cfg" or
+	// "/etc/pki/ca-trust/source/anchors/corp.pem". A file already at path
+	// in the initrd is replaced; missing parent directories are created
+	// with mode 0755.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	Path string `json:"path"`
+
+	// mode is the file's permission bits, 0644 when omitted. YAML accepts
+	// octal values such as 0755; JSON requires decimal values.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4095
+	Mode *int32 `json:"mode,omitempty"`
+
+	// configMapKeyRef selects a ConfigMap key holding the file, from its
+	// data or binaryData.
+	// +optional
+	ConfigMapKeyRef *ConfigMapKeyReference `json:"configMapKeyRef,omitempty"`
+
+	// secretKeyRef selects a Secret key holding the file.
+	// +optional
+	SecretKeyRef *SecretKeyReference `json:"secretKeyRef,omitempty"`
+
+	// artifactRef is the name of a BootArtifact whose file is added as-is.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	ArtifactRef string `json:"artifactRef,omitempty"`
 }
 
 // BootConfigNetbootSpec defines direct PXE netboot artifacts (mode A).
@@ -59,8 +231,10 @@ type BootConfigNetbootSpec struct {
 // BootConfigSpec defines the desired state of BootConfig.
 // A BootConfig groups BootArtifacts into a servable PXE boot directory.
 // The directory name is metadata.name.
-// Exactly one mode: netboot (direct kernel + initrd refs) or iso (ISO extraction).
-// +kubebuilder:validation:XValidation:rule="has(self.netboot) != has(self.iso)",message="must set exactly one of netboot or iso"
+// Exactly one mode: netboot (direct kernel + initrd refs), iso (ISO
+// extraction), archive (tar or zip extraction) or diskImage (disk image
+// extraction).
+// +kubebuilder:validation:XValidation:rule="[has(self.netboot), has(self.iso), has(self.archive), has(self.diskImage)].filter(m, m).size() == 1",message="must set exactly one of netboot, iso, archive or diskImage"
 type BootConfigSpec struct {
 	// netboot defines direct PXE kernel/initrd artifacts (mode A).
 	// +optional
@@ -70,8 +244,30 @@ type BootConfigSpec struct {
 	// +optional
 	ISO *BootConfigISOSpec `json:"iso,omitempty"`
 
-	// kernelArgs is the kernel boot arguments template string, applied in both
+	// archive defines tar or zip archive extraction configuration.
+	// +optional
+	Archive *BootConfigArchiveSpec `json:"archive,omitempty"`
+
+	// diskImage defines raw or GPT disk image extraction configuration.
+	// +optional
+	DiskImage *BootConfigDiskImageSpec `json:"diskImage,omitempty"`
+
+	// initrdOverlays are files packed into a cpio archive appended to the
+	// served initrd in every mode, after the firmware in netboot mode.
+	// ConfigMaps and Secrets are not watched; changes to them are picked up
+	// within a minute.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	InitrdOverlays []BootConfigInitrdOverlay `json:"initrdOverlays,omitempty"`
+
+	// kernelArgs is the kernel boot arguments template string, applied in all
 	// modes. May contain Go template variables interpolated at provision time.
+	// In iso mode the arguments of the ISO's default boot entry
+	// (status.iso.kernelArgs) are used when it is empty. Those that find or
+	// check the disc the ISO boots from, such as inst.stage2=hd:...,
+	// root=live:CDLABEL=... and rd.live.check, are left out, so an installer
+	// that needs a stage 2 or live root must be given one here, e.g.
+	// inst.stage2 pointing at an HTTP mirror.
 	// +optional
 	KernelArgs string `json:"kernelArgs,omitempty"`
 }
@@ -86,6 +282,39 @@ const (
 	BootConfigPhaseError   BootConfigPhase = "Error"
 )
 
+// BootConfigISOStatus describes the ISO of an iso mode BootConfig.
+type BootConfigISOStatus struct {
+	// distro is the distribution named by the ISO's .treeinfo or
+	// .disk/info, e.g. "Rocky Linux" or "Ubuntu-Server".
+	// +optional
+	Distro string `json:"distro,omitempty"`
+
+	// version is the release named by the ISO's .treeinfo or .disk/info.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// kernelPath is the path within the ISO the kernel was extracted from,
+	// as given in spec.iso or detected.
+	// +optional
+	KernelPath string `json:"kernelPath,omitempty"`
+
+	// initrdPath is the path within the ISO the initrd was extracted from,
+	// as given in spec.iso or detected.
+	// +optional
+	InitrdPath string `json:"initrdPath,omitempty"`
+
+	// kernelArgs are the arguments of the ISO's default boot entry, without
+	// its kernel and initrd and those bound to the boot media.
+	// +optional
+	KernelArgs string `json:"kernelArgs,omitempty"`
+
+	// files lists the top-level directory of the ISO; directories end in
+	// "/". It is truncated to 64 entries.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Files []string `json:"files,omitempty"`
+}
+
 // BootConfigStatus defines the observed state of BootConfig.
 type BootConfigStatus struct {
 	// phase is the current phase of the boot config.
@@ -95,6 +324,22 @@ type BootConfigStatus struct {
 	// message provides human-readable details about the current phase.
 	// +optional
 	Message string `json:"message,omitempty"`
+
+	// observedGeneration is the metadata.generation the status was last
+	// computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// iso reports what was found in the ISO in iso mode.
+	// +optional
+	ISO *BootConfigISOStatus `json:"iso,omitempty"`
+
+	// conditions represent the latest available observations of the boot
+	// config's state: Ready, Progressing, Degraded and ReferencesResolved.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -119,6 +364,20 @@ type BootConfig struct {
 	Status BootConfigStatus `json:"status,omitzero"`
 }
 
+// OverlaidInitrdDir is the directory, within the boot directory, holding
+// the initrd served in iso, archive and diskImage modes when
+// spec.initrdOverlays is set: the extracted initrd followed by the overlay.
+const OverlaidInitrdDir = "overlaid"
+
+// ExtractedInitrdPath returns the path, relative to the boot directory, of
+// the initrd served in iso, archive or diskImage mode.
+func (bc *BootConfig) ExtractedInitrdPath() string {
+	if len(bc.Spec.InitrdOverlays) > 0 {
+		return path.Join(OverlaidInitrdDir, "initrd")
+	}
+	return "initrd"
+}
+
 // +kubebuilder:object:root=true
 
 // BootConfigList contains a list of BootConfig
@@ -0,0 +1,38 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// Condition types shared by the isoboot CRDs. Each resource sets the ones
+// that apply to it in status.conditions.
+const (
+	// ConditionReady is True when the resource can be used: a BootArtifact's
+	// file is verified, a BootConfig's boot directory is assembled, a
+	// Provision is complete, a Machine or ProvisionAutomation is valid.
+	ConditionReady = "Ready"
+
+	// ConditionProgressing is True while the controller is working towards
+	// Ready, e.g. a download is queued or running.
+	ConditionProgressing = "Progressing"
+
+	// ConditionDegraded is True when the last attempt failed; the reason
+	// says why.
+	ConditionDegraded = "Degraded"
+
+	// ConditionReferencesResolved is True when every object the resource
+	// refers to by name exists.
+	ConditionReferencesResolved = "ReferencesResolved"
+)
@@ -28,9 +28,27 @@ type MachineSpec struct {
 	MAC string `json:"mac"`
 }
 
+// MachineStatus defines the observed state of Machine.
+type MachineStatus struct {
+	// observedGeneration is the metadata.generation the conditions were last
+	// computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// conditions represent the latest available observations of the
+	// machine's state. Ready is False while another Machine in the namespace
+	// has the same MAC address.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=mach
 // +kubebuilder:printcolumn:name="MAC",type=string,JSONPath=".spec.mac"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
 
 // Machine is the Schema for the machines API.
@@ -44,6 +62,10 @@ type Machine struct {
 	// spec defines the desired state of Machine
 	// +required
 	Spec MachineSpec `json:"spec"`
+
+	// status defines the observed state of Machine
+	// +optional
+	Status MachineStatus `json:"status,omitzero"`
 }
 
 // +kubebuilder:object:root=true
@@ -77,6 +77,18 @@ type ProvisionStatus struct {
 	// ip is the IP address assigned to the machine during provisioning.
 	// +optional
 	IP string `json:"ip,omitempty"`
+
+	// observedGeneration is the metadata.generation the conditions were last
+	// computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// conditions represent the latest available observations of the
+	// provision's state: Ready, Progressing, Degraded and ReferencesResolved.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
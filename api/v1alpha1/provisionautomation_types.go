@@ -29,8 +29,26 @@ type ProvisionAutomationSpec struct {
 	Files map[string]string `json:"files"`
 }
 
+// ProvisionAutomationStatus defines the observed state of ProvisionAutomation.
+type ProvisionAutomationStatus struct {
+	// observedGeneration is the metadata.generation the conditions were last
+	// computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// conditions represent the latest available observations of the
+	// provision automation's state. Ready is False while a file template
+	// does not parse.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=pa
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
 
 // ProvisionAutomation is the Schema for the provisionautomations API.
@@ -44,6 +62,10 @@ type ProvisionAutomation struct {
 	// spec defines the desired state of ProvisionAutomation
 	// +required
 	Spec ProvisionAutomationSpec `json:"spec"`
+
+	// status defines the observed state of ProvisionAutomation
+	// +optional
+	Status ProvisionAutomationStatus `json:"status,omitzero"`
 }
 
 // +kubebuilder:object:root=true
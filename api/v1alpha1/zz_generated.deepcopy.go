@@ -21,9 +21,88 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactMirrorPolicy) DeepCopyInto(out *ArtifactMirrorPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactMirrorPolicy.
+func (in *ArtifactMirrorPolicy) DeepCopy() *ArtifactMirrorPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactMirrorPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArtifactMirrorPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactMirrorPolicyList) DeepCopyInto(out *ArtifactMirrorPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ArtifactMirrorPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactMirrorPolicyList.
+func (in *ArtifactMirrorPolicyList) DeepCopy() *ArtifactMirrorPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactMirrorPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArtifactMirrorPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactMirrorPolicySpec) DeepCopyInto(out *ArtifactMirrorPolicySpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]URLRewriteRule, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactMirrorPolicySpec.
+func (in *ArtifactMirrorPolicySpec) DeepCopy() *ArtifactMirrorPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactMirrorPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootArtifact) DeepCopyInto(out *BootArtifact) {
 	*out = *in
@@ -51,6 +130,47 @@ func (in *BootArtifact) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootArtifactAuth) DeepCopyInto(out *BootArtifactAuth) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(SecretReference)
+		**out = **in
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(ConfigMapKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootArtifactAuth.
+func (in *BootArtifactAuth) DeepCopy() *BootArtifactAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(BootArtifactAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootArtifactChecksums) DeepCopyInto(out *BootArtifactChecksums) {
+	*out = *in
+	out.Keyring = in.Keyring
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootArtifactChecksums.
+func (in *BootArtifactChecksums) DeepCopy() *BootArtifactChecksums {
+	if in == nil {
+		return nil
+	}
+	out := new(BootArtifactChecksums)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootArtifactList) DeepCopyInto(out *BootArtifactList) {
 	*out = *in
@@ -83,9 +203,57 @@ func (in *BootArtifactList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootArtifactProgress) DeepCopyInto(out *BootArtifactProgress) {
+	*out = *in
+	if in.ETA != nil {
+		in, out := &in.ETA, &out.ETA
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootArtifactProgress.
+func (in *BootArtifactProgress) DeepCopy() *BootArtifactProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(BootArtifactProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootArtifactProvenance) DeepCopyInto(out *BootArtifactProvenance) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootArtifactProvenance.
+func (in *BootArtifactProvenance) DeepCopy() *BootArtifactProvenance {
+	if in == nil {
+		return nil
+	}
+	out := new(BootArtifactProvenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootArtifactSpec) DeepCopyInto(out *BootArtifactSpec) {
 	*out = *in
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.SHA256 != nil {
 		in, out := &in.SHA256, &out.SHA256
 		*out = new(string)
@@ -96,6 +264,26 @@ func (in *BootArtifactSpec) DeepCopyInto(out *BootArtifactSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Checksums != nil {
+		in, out := &in.Checksums, &out.Checksums
+		*out = new(BootArtifactChecksums)
+		**out = **in
+	}
+	if in.ImagePullSecret != nil {
+		in, out := &in.ImagePullSecret, &out.ImagePullSecret
+		*out = new(SecretReference)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(BootArtifactAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DriftCheckInterval != nil {
+		in, out := &in.DriftCheckInterval, &out.DriftCheckInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootArtifactSpec.
@@ -119,6 +307,27 @@ func (in *BootArtifactStatus) DeepCopyInto(out *BootArtifactStatus) {
 		in, out := &in.LastChecked, &out.LastChecked
 		*out = (*in).DeepCopy()
 	}
+	if in.Provenance != nil {
+		in, out := &in.Provenance, &out.Provenance
+		*out = new(BootArtifactProvenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastDriftCheck != nil {
+		in, out := &in.LastDriftCheck, &out.LastDriftCheck
+		*out = (*in).DeepCopy()
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(BootArtifactProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootArtifactStatus.
@@ -137,7 +346,7 @@ func (in *BootConfig) DeepCopyInto(out *BootConfig) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootConfig.
@@ -158,9 +367,74 @@ func (in *BootConfig) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootConfigArchiveSpec) DeepCopyInto(out *BootConfigArchiveSpec) {
+	*out = *in
+	if in.ExtraFiles != nil {
+		in, out := &in.ExtraFiles, &out.ExtraFiles
+		*out = make([]BootConfigISOFile, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootConfigArchiveSpec.
+func (in *BootConfigArchiveSpec) DeepCopy() *BootConfigArchiveSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BootConfigArchiveSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootConfigDiskImageSpec) DeepCopyInto(out *BootConfigDiskImageSpec) {
+	*out = *in
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExtraFiles != nil {
+		in, out := &in.ExtraFiles, &out.ExtraFiles
+		*out = make([]BootConfigISOFile, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootConfigDiskImageSpec.
+func (in *BootConfigDiskImageSpec) DeepCopy() *BootConfigDiskImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BootConfigDiskImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootConfigISOFile) DeepCopyInto(out *BootConfigISOFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootConfigISOFile.
+func (in *BootConfigISOFile) DeepCopy() *BootConfigISOFile {
+	if in == nil {
+		return nil
+	}
+	out := new(BootConfigISOFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootConfigISOSpec) DeepCopyInto(out *BootConfigISOSpec) {
 	*out = *in
+	if in.ExtraFiles != nil {
+		in, out := &in.ExtraFiles, &out.ExtraFiles
+		*out = make([]BootConfigISOFile, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootConfigISOSpec.
@@ -173,6 +447,56 @@ func (in *BootConfigISOSpec) DeepCopy() *BootConfigISOSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootConfigISOStatus) DeepCopyInto(out *BootConfigISOStatus) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootConfigISOStatus.
+func (in *BootConfigISOStatus) DeepCopy() *BootConfigISOStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BootConfigISOStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootConfigInitrdOverlay) DeepCopyInto(out *BootConfigInitrdOverlay) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(ConfigMapKeyReference)
+		**out = **in
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootConfigInitrdOverlay.
+func (in *BootConfigInitrdOverlay) DeepCopy() *BootConfigInitrdOverlay {
+	if in == nil {
+		return nil
+	}
+	out := new(BootConfigInitrdOverlay)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootConfigList) DeepCopyInto(out *BootConfigList) {
 	*out = *in
@@ -231,7 +555,24 @@ func (in *BootConfigSpec) DeepCopyInto(out *BootConfigSpec) {
 	if in.ISO != nil {
 		in, out := &in.ISO, &out.ISO
 		*out = new(BootConfigISOSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(BootConfigArchiveSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiskImage != nil {
+		in, out := &in.DiskImage, &out.DiskImage
+		*out = new(BootConfigDiskImageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InitrdOverlays != nil {
+		in, out := &in.InitrdOverlays, &out.InitrdOverlays
+		*out = make([]BootConfigInitrdOverlay, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -248,6 +589,18 @@ func (in *BootConfigSpec) DeepCopy() *BootConfigSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootConfigStatus) DeepCopyInto(out *BootConfigStatus) {
 	*out = *in
+	if in.ISO != nil {
+		in, out := &in.ISO, &out.ISO
+		*out = new(BootConfigISOStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootConfigStatus.
@@ -260,12 +613,28 @@ func (in *BootConfigStatus) DeepCopy() *BootConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyReference) DeepCopyInto(out *ConfigMapKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyReference.
+func (in *ConfigMapKeyReference) DeepCopy() *ConfigMapKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Machine) DeepCopyInto(out *Machine) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Machine.
@@ -333,6 +702,28 @@ func (in *MachineSpec) DeepCopy() *MachineSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineStatus) DeepCopyInto(out *MachineStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineStatus.
+func (in *MachineStatus) DeepCopy() *MachineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Provision) DeepCopyInto(out *Provision) {
 	*out = *in
@@ -366,6 +757,7 @@ func (in *ProvisionAutomation) DeepCopyInto(out *ProvisionAutomation) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionAutomation.
@@ -440,6 +832,28 @@ func (in *ProvisionAutomationSpec) DeepCopy() *ProvisionAutomationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisionAutomationStatus) DeepCopyInto(out *ProvisionAutomationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionAutomationStatus.
+func (in *ProvisionAutomationStatus) DeepCopy() *ProvisionAutomationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisionAutomationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProvisionList) DeepCopyInto(out *ProvisionList) {
 	*out = *in
@@ -504,6 +918,13 @@ func (in *ProvisionStatus) DeepCopyInto(out *ProvisionStatus) {
 		in, out := &in.LastUpdated, &out.LastUpdated
 		*out = (*in).DeepCopy()
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionStatus.
@@ -515,3 +936,48 @@ func (in *ProvisionStatus) DeepCopy() *ProvisionStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretReference) DeepCopyInto(out *SecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretReference.
+func (in *SecretReference) DeepCopy() *SecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *URLRewriteRule) DeepCopyInto(out *URLRewriteRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new URLRewriteRule.
+func (in *URLRewriteRule) DeepCopy() *URLRewriteRule {
+	if in == nil {
+		return nil
+	}
+	out := new(URLRewriteRule)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// isoboot-bundle exports BootArtifacts, BootConfigs and ProvisionAutomations
+// together with the verified artifact files into one tarball, and imports
+// such a bundle at a disconnected site. It runs where both the cluster and
+// the controller's data directory are reachable, e.g. on the controller node.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+	"github.com/isoboot/isoboot/internal/bundle"
+)
+
+// defaultDataDir matches the controller's --data-dir default.
+const defaultDataDir = "/data/isoboot"
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage:
+  isoboot-bundle [--kubeconfig=...] export --output=FILE [--namespace=NS] [--selector=SELECTOR] [--data-dir=DIR]
+  isoboot-bundle [--kubeconfig=...] import --input=FILE [--namespace=NS] [--data-dir=DIR]
+
+export writes the selected BootArtifacts, BootConfigs and ProvisionAutomations,
+the BootArtifacts the BootConfigs reference, and the verified files of all
+those BootArtifacts to FILE. import writes the files to the data directory
+and creates the objects; the BootArtifacts become Ready without a download.
+`)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	ctx := ctrl.SetupSignalHandler()
+	var err error
+	switch flag.Arg(0) {
+	case "export":
+		err = runExport(ctx, flag.Args()[1:])
+	case "import":
+		err = runImport(ctx, flag.Args()[1:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "isoboot-bundle: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newClient() (client.Client, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	sch := runtime.NewScheme()
+	utilruntime.Must(isobootgithubiov1alpha1.AddToScheme(sch))
+	return client.New(cfg, client.Options{Scheme: sch})
+}
+
+func runExport(ctx context.Context, args []string) (err error) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("output", "", "bundle file to write")
+	namespace := fs.String("namespace", "default", "namespace to export from")
+	selector := fs.String("selector", "", "label selector for the objects to export; empty selects all")
+	dataDir := fs.String("data-dir", defaultDataDir, "the controller's --data-dir")
+	_ = fs.Parse(args)
+	if *output == "" {
+		return errors.New("export: --output is required")
+	}
+	sel, err := labels.Parse(*selector)
+	if err != nil {
+		return fmt.Errorf("export: --selector: %w", err)
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(*output)
+		}
+	}()
+	manifest, err := bundle.Export(ctx, c, bundle.ExportOptions{Namespace: *namespace, Selector: sel, DataDir: *dataDir}, f)
+	if err != nil {
+		return err
+	}
+	for _, a := range manifest.Artifacts {
+		fmt.Printf("exported BootArtifact %s/%s (%s)\n", a.Namespace, a.Name, a.Digest)
+	}
+	return nil
+}
+
+func runImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	input := fs.String("input", "", "bundle file to read")
+	namespace := fs.String("namespace", "", "namespace to create the objects in; empty keeps the exported namespace")
+	dataDir := fs.String("data-dir", defaultDataDir, "the controller's --data-dir")
+	_ = fs.Parse(args)
+	if *input == "" {
+		return errors.New("import: --input is required")
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	result, err := bundle.Import(ctx, c, bundle.ImportOptions{DataDir: *dataDir, Namespace: *namespace}, f)
+	if result != nil {
+		for _, obj := range result.Created {
+			fmt.Printf("created %s\n", obj)
+		}
+		for _, obj := range result.Skipped {
+			fmt.Printf("skipped %s: already exists\n", obj)
+		}
+	}
+	return err
+}
@@ -206,6 +206,10 @@ func conditionalBootHandler(
 			if directive.ISOPath != "" {
 				isoURL = fmt.Sprintf("http://%s/static/%s", host, directive.ISOPath)
 			}
+			files := make(map[string]string, len(directive.Files))
+			for name, p := range directive.Files {
+				files[name] = fmt.Sprintf("http://%s/static/%s", host, p)
+			}
 			rendered, err := httpd.RenderKernelArgs(
 				directive.KernelArgs, httpd.KernelArgsData{
 					ProvisionAutomationBaseURL: baseURL,
@@ -213,6 +217,7 @@ func conditionalBootHandler(
 					UpdatePhaseURL:             statusURL,
 					ProvisionName:              directive.ProvisionName,
 					ISOURL:                     isoURL,
+					Files:                      files,
 				})
 			if err != nil {
 				slog.Error("kernel args template failed",
@@ -22,18 +22,22 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -58,6 +62,12 @@ func init() {
 // nolint:gocyclo
 func main() {
 	var dataDir string
+	var maxConcurrentDownloads int
+	var downloadRateLimit int64
+	var verifyInterval time.Duration
+	var minFreeSpace int64
+	var evictUnreferenced bool
+	var fileSourceRoot string
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -67,6 +77,20 @@ func main() {
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&dataDir, "data-dir", "/data/isoboot", "Base directory for storing artifacts and boot configs.")
+	flag.IntVar(&maxConcurrentDownloads, "max-concurrent-downloads", 3,
+		"Maximum number of BootArtifact downloads to run at once. 0 means unlimited.")
+	flag.Int64Var(&downloadRateLimit, "download-rate-limit", 0,
+		"Combined bandwidth limit for BootArtifact downloads, in bytes per second. 0 means unlimited.")
+	flag.DurationVar(&verifyInterval, "verify-interval", 24*time.Hour,
+		"How often stored BootArtifact files are re-hashed to detect corruption. 0 disables periodic verification.")
+	flag.Int64Var(&minFreeSpace, "min-free-space", 1<<30,
+		"Bytes a BootArtifact download must leave free in the data directory; larger downloads are refused.")
+	flag.BoolVar(&evictUnreferenced, "evict-unreferenced", false,
+		"Remove the files of BootArtifacts no BootConfig references, least recently accessed first, "+
+			"when a download needs the space.")
+	flag.StringVar(&fileSourceRoot, "file-source-root", "",
+		"Directory that file:// BootArtifact sources are read from, such as a mounted volume. "+
+			"Empty disables file:// sources.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -92,6 +116,15 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if fileSourceRoot != "" {
+		abs, err := filepath.Abs(fileSourceRoot)
+		if err != nil {
+			setupLog.Error(err, "Invalid --file-source-root")
+			os.Exit(1)
+		}
+		fileSourceRoot = abs
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -166,6 +199,14 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "423af48d.isoboot.github.io",
+		// Keyring ConfigMaps and image pull Secrets are read directly from the
+		// API server rather than cached, so the manager does not need
+		// list/watch on all ConfigMaps and Secrets.
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				DisableFor: []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}},
+			},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -191,11 +232,16 @@ func main() {
 	}
 
 	if err := (&controller.BootArtifactReconciler{
-		Client:     mgr.GetClient(),
-		Scheme:     mgr.GetScheme(),
-		DataDir:    dataDir,
-		HTTPClient: &http.Client{Timeout: 30 * time.Minute},
-		Recorder:   mgr.GetEventRecorder("bootartifact-controller"),
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		DataDir:           dataDir,
+		HTTPClient:        &http.Client{Timeout: 30 * time.Minute},
+		Recorder:          mgr.GetEventRecorder("bootartifact-controller"),
+		Scheduler:         controller.NewDownloadScheduler(maxConcurrentDownloads, downloadRateLimit),
+		VerifyInterval:    verifyInterval,
+		MinFreeSpace:      minFreeSpace,
+		EvictUnreferenced: evictUnreferenced,
+		FileSourceRoot:    fileSourceRoot,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Failed to create controller", "controller", "BootArtifact")
 		os.Exit(1)
@@ -215,13 +261,30 @@ func main() {
 		setupLog.Error(err, "Failed to create controller", "controller", "Provision")
 		os.Exit(1)
 	}
+	if err := (&controller.MachineReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "Failed to create controller", "controller", "Machine")
+		os.Exit(1)
+	}
+	if err := (&controller.ProvisionAutomationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "Failed to create controller", "controller", "ProvisionAutomation")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	metrics.Registry.MustRegister(controller.NewPhaseCollector(mgr.GetClient()),
+		controller.NewDataDirCollector(dataDir, minFreeSpace))
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "Failed to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	if err := mgr.AddReadyzCheck("data-dir", controller.DataDirCheck(dataDir, minFreeSpace)); err != nil {
 		setupLog.Error(err, "Failed to set up ready check")
 		os.Exit(1)
 	}
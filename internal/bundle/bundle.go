@@ -0,0 +1,589 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bundle moves BootArtifacts, BootConfigs and ProvisionAutomations,
+// together with the verified files of the BootArtifacts, to a disconnected
+// site as a single tar archive. The archive holds, in this order:
+//
+//	manifest.json                     the Manifest, listing every file and its digest
+//	resources.yaml                    the exported objects, without status
+//	artifacts/<namespace>/<name>/<f>  the file of each BootArtifact
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+	"github.com/isoboot/isoboot/internal/controller"
+	"github.com/isoboot/isoboot/internal/urlutil"
+)
+
+// ManifestVersion is the bundle format version written by Export.
+const ManifestVersion = 1
+
+const (
+	manifestName  = "manifest.json"
+	resourcesName = "resources.yaml"
+	filesDir      = "artifacts"
+
+	// maxMetadataSize bounds manifest.json and resources.yaml on import.
+	maxMetadataSize = 16 << 20
+)
+
+// Manifest describes the artifact files in a bundle.
+type Manifest struct {
+	Version   int            `json:"version"`
+	Artifacts []ArtifactFile `json:"artifacts"`
+}
+
+// ArtifactFile is the verified file of one BootArtifact.
+type ArtifactFile struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Path is the name of the file in the archive.
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Digest is the digest of the file, "sha256:<hex>" or "sha512:<hex>".
+	Digest string `json:"digest"`
+	// ResolvedDigest and DecompressedDigest carry the BootArtifact status
+	// fields of the same name, so the importing site does not have to
+	// resolve spec.checksums or an OCI tag again.
+	ResolvedDigest     string `json:"resolvedDigest,omitempty"`
+	DecompressedDigest string `json:"decompressedDigest,omitempty"`
+}
+
+// ExportOptions selects what Export writes.
+type ExportOptions struct {
+	// Namespace is the namespace to export from.
+	Namespace string
+	// Selector selects BootArtifacts, BootConfigs and ProvisionAutomations
+	// by label; nil selects everything. BootArtifacts that a selected
+	// BootConfig references are exported as well.
+	Selector labels.Selector
+	// DataDir is the controller's --data-dir holding the artifact files.
+	DataDir string
+}
+
+// Export writes a bundle of the selected objects to w. Every BootArtifact in
+// it must be Ready, and its file is hashed again while it is written, so a
+// bundle only ever carries files that match their digest.
+func Export(ctx context.Context, c client.Reader, opts ExportOptions, w io.Writer) (*Manifest, error) {
+	sel := opts.Selector
+	if sel == nil {
+		sel = labels.Everything()
+	}
+	listOpts := []client.ListOption{client.InNamespace(opts.Namespace), client.MatchingLabelsSelector{Selector: sel}}
+	var artifacts isobootgithubiov1alpha1.BootArtifactList
+	if err := c.List(ctx, &artifacts, listOpts...); err != nil {
+		return nil, fmt.Errorf("listing BootArtifacts: %w", err)
+	}
+	var configs isobootgithubiov1alpha1.BootConfigList
+	if err := c.List(ctx, &configs, listOpts...); err != nil {
+		return nil, fmt.Errorf("listing BootConfigs: %w", err)
+	}
+	var automations isobootgithubiov1alpha1.ProvisionAutomationList
+	if err := c.List(ctx, &automations, listOpts...); err != nil {
+		return nil, fmt.Errorf("listing ProvisionAutomations: %w", err)
+	}
+
+	selected := map[string]*isobootgithubiov1alpha1.BootArtifact{}
+	for i := range artifacts.Items {
+		selected[artifacts.Items[i].Name] = &artifacts.Items[i]
+	}
+	for i := range configs.Items {
+		for _, ref := range controller.ArtifactRefs(&configs.Items[i]) {
+			if _, ok := selected[ref]; ok {
+				continue
+			}
+			var a isobootgithubiov1alpha1.BootArtifact
+			if err := c.Get(ctx, client.ObjectKey{Namespace: opts.Namespace, Name: ref}, &a); err != nil {
+				return nil, fmt.Errorf("BootConfig %s references BootArtifact %s: %w", configs.Items[i].Name, ref, err)
+			}
+			selected[ref] = &a
+		}
+	}
+	manifest := &Manifest{Version: ManifestVersion}
+	var objects []client.Object
+	for _, name := range slices.Sorted(maps.Keys(selected)) {
+		a := selected[name]
+		if a.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady || a.Status.ObservedGeneration != a.Generation {
+			return nil, fmt.Errorf("BootArtifact %s is not Ready", a.Name)
+		}
+		d, err := fileDigest(a)
+		if err != nil {
+			return nil, err
+		}
+		file := urlutil.ArtifactFilename(a.Spec.URL, string(a.Spec.Decompress))
+		info, err := os.Stat(filepath.Join(opts.DataDir, filesDir, a.Name, file))
+		if err != nil {
+			return nil, fmt.Errorf("BootArtifact %s: %w", a.Name, err)
+		}
+		manifest.Artifacts = append(manifest.Artifacts, ArtifactFile{
+			Namespace:          a.Namespace,
+			Name:               a.Name,
+			Path:               path.Join(filesDir, a.Namespace, a.Name, file),
+			Size:               info.Size(),
+			Digest:             d,
+			ResolvedDigest:     a.Status.ResolvedDigest,
+			DecompressedDigest: a.Status.DecompressedDigest,
+		})
+		a.Status = isobootgithubiov1alpha1.BootArtifactStatus{}
+		objects = append(objects, exportable(a, "BootArtifact"))
+	}
+	for i := range automations.Items {
+		pa := &automations.Items[i]
+		pa.Status = isobootgithubiov1alpha1.ProvisionAutomationStatus{}
+		objects = append(objects, exportable(pa, "ProvisionAutomation"))
+	}
+	for i := range configs.Items {
+		bc := &configs.Items[i]
+		bc.Status = isobootgithubiov1alpha1.BootConfigStatus{}
+		objects = append(objects, exportable(bc, "BootConfig"))
+	}
+
+	tw := tar.NewWriter(w)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestName, data); err != nil {
+		return nil, err
+	}
+	var resources bytes.Buffer
+	for _, obj := range objects {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", obj.GetName(), err)
+		}
+		resources.WriteString("---\n")
+		resources.Write(data)
+	}
+	if err := writeEntry(tw, resourcesName, resources.Bytes()); err != nil {
+		return nil, err
+	}
+	for _, entry := range manifest.Artifacts {
+		if err := writeArtifactEntry(tw, filepath.Join(opts.DataDir, filesDir, entry.Name, path.Base(entry.Path)), entry); err != nil {
+			return nil, fmt.Errorf("BootArtifact %s: %w", entry.Name, err)
+		}
+	}
+	return manifest, tw.Close()
+}
+
+// fileDigest returns the digest of the file the controller serves for a:
+// the decompressed file's for spec.decompress, else the configured or
+// resolved digest of the download.
+func fileDigest(a *isobootgithubiov1alpha1.BootArtifact) (string, error) {
+	var d string
+	switch {
+	case a.Spec.Decompress != "":
+		d = a.Status.DecompressedDigest
+	case a.Spec.SHA256 != nil:
+		d = "sha256:" + *a.Spec.SHA256
+	case a.Spec.SHA512 != nil:
+		d = "sha512:" + *a.Spec.SHA512
+	default:
+		d = a.Status.ResolvedDigest
+	}
+	if d == "" {
+		return "", fmt.Errorf("BootArtifact %s has no recorded digest", a.Name)
+	}
+	return strings.ToLower(d), nil
+}
+
+// exportable returns obj with its type set and the fields that only mean
+// something in the source cluster cleared.
+func exportable(obj client.Object, kind string) client.Object {
+	obj.GetObjectKind().SetGroupVersionKind(isobootgithubiov1alpha1.GroupVersion.WithKind(kind))
+	obj.SetUID("")
+	obj.SetResourceVersion("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetManagedFields(nil)
+	obj.SetFinalizers(nil)
+	obj.SetOwnerReferences(nil)
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		obj.SetAnnotations(annotations)
+	}
+	return obj
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: time.Now(), Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// writeArtifactEntry copies the file at filePath into the archive, checking
+// it against entry.Digest on the way.
+func writeArtifactEntry(tw *tar.Writer, filePath string, entry ArtifactFile) error {
+	h, want, err := newHash(entry.Digest)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	if err := tw.WriteHeader(&tar.Header{
+		Name: entry.Path, Mode: 0o644, Size: entry.Size, ModTime: time.Now(), Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(io.MultiWriter(tw, h), f, entry.Size); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("digest mismatch: expected %s, got %s", want, got)
+	}
+	return nil
+}
+
+// newHash returns the hash and the lowercase hex value of a
+// "<algorithm>:<hex>" digest.
+func newHash(d string) (hash.Hash, string, error) {
+	algorithm, hexValue, _ := strings.Cut(d, ":")
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), strings.ToLower(hexValue), nil
+	case "sha512":
+		return sha512.New(), strings.ToLower(hexValue), nil
+	}
+	return nil, "", fmt.Errorf("unsupported digest %q", d)
+}
+
+// ImportOptions controls Import.
+type ImportOptions struct {
+	// DataDir is the controller's --data-dir the artifact files go to.
+	DataDir string
+	// Namespace, when set, replaces the namespace of every imported object.
+	Namespace string
+}
+
+// ImportResult lists the imported objects as "<Kind> <namespace>/<name>".
+type ImportResult struct {
+	Created []string
+	// Skipped objects already existed and were left unchanged.
+	Skipped []string
+}
+
+// Import reads a bundle written by Export. Every file is verified against the
+// manifest while it is written to DataDir/artifacts/<name>/, and only then
+// are the objects created: BootArtifacts first, so the controller finds
+// their files in place and sets them Ready without downloading, then
+// ProvisionAutomations and BootConfigs. Resolved and decompressed digests
+// travel in the BootArtifactAnnotationImportedDigests annotation, which the
+// controller adopts on its first reconcile. The files of BootArtifacts that
+// already exist are not written, and a bundle holding a BootArtifact whose
+// name is taken in another namespace is refused.
+func Import(ctx context.Context, c client.Client, opts ImportOptions, r io.Reader) (*ImportResult, error) {
+	tr := tar.NewReader(r)
+	data, err := readEntry(tr, manifestName)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", manifestName, err)
+	}
+	if manifest.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", manifest.Version)
+	}
+	data, err = readEntry(tr, resourcesName)
+	if err != nil {
+		return nil, err
+	}
+	artifacts, automations, configs, err := decodeResources(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each file must belong to a BootArtifact in the bundle and have the
+	// path the controller would give it, so a bundle cannot write anywhere
+	// else in the data directory.
+	entries := map[string]ArtifactFile{}
+	dirs := map[string]ArtifactFile{}
+	for _, entry := range manifest.Artifacts {
+		i := slices.IndexFunc(artifacts, func(a *isobootgithubiov1alpha1.BootArtifact) bool {
+			return a.Namespace == entry.Namespace && a.Name == entry.Name
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%s: no BootArtifact %s/%s in bundle", entry.Path, entry.Namespace, entry.Name)
+		}
+		a := artifacts[i]
+		if errs := validation.IsDNS1123Subdomain(a.Name); len(errs) > 0 {
+			return nil, fmt.Errorf("BootArtifact name %q: %s", a.Name, strings.Join(errs, ", "))
+		}
+		if want := path.Join(filesDir, a.Namespace, a.Name, urlutil.ArtifactFilename(a.Spec.URL, string(a.Spec.Decompress))); entry.Path != want {
+			return nil, fmt.Errorf("%s: expected %s for BootArtifact %s/%s", entry.Path, want, a.Namespace, a.Name)
+		}
+		// The controller stores files by name alone.
+		if other, ok := dirs[a.Name]; ok {
+			return nil, fmt.Errorf("BootArtifacts %s/%s and %s/%s would share a file", other.Namespace, other.Name, a.Namespace, a.Name)
+		}
+		dirs[a.Name] = entry
+		entries[entry.Path] = entry
+	}
+
+	// A BootArtifact that already exists keeps its file and is skipped. One
+	// of the same name in another namespace has the file the bundle would
+	// write, so the bundle is refused.
+	var existing isobootgithubiov1alpha1.BootArtifactList
+	if err := c.List(ctx, &existing); err != nil {
+		return nil, fmt.Errorf("listing BootArtifacts: %w", err)
+	}
+	keep := map[string]bool{}
+	for _, entry := range manifest.Artifacts {
+		namespace := cmp.Or(opts.Namespace, entry.Namespace)
+		for _, a := range existing.Items {
+			switch {
+			case a.Name != entry.Name:
+			case a.Namespace == namespace:
+				keep[entry.Path] = true
+			default:
+				return nil, fmt.Errorf("%s would overwrite the file of BootArtifact %s/%s", entry.Path, a.Namespace, a.Name)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(opts.DataDir, filesDir), 0o755); err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(filepath.Join(opts.DataDir, filesDir))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle: %w", err)
+		}
+		entry, ok := entries[hdr.Name]
+		if !ok || hdr.Typeflag != tar.TypeReg || hdr.Size != entry.Size {
+			return nil, fmt.Errorf("unexpected entry %s in bundle", hdr.Name)
+		}
+		if !keep[hdr.Name] {
+			if err := importArtifactFile(root, entry, tr); err != nil {
+				return nil, fmt.Errorf("%s: %w", entry.Path, err)
+			}
+		}
+		delete(entries, hdr.Name)
+	}
+	if len(entries) > 0 {
+		missing := slices.Sorted(maps.Keys(entries))
+		return nil, fmt.Errorf("bundle is missing %s", strings.Join(missing, ", "))
+	}
+
+	result := &ImportResult{}
+	for _, a := range artifacts {
+		i := slices.IndexFunc(manifest.Artifacts, func(e ArtifactFile) bool {
+			return e.Namespace == a.Namespace && e.Name == a.Name
+		})
+		if i >= 0 {
+			annotateDigests(a, manifest.Artifacts[i])
+		}
+		if err := create(ctx, c, opts, a, "BootArtifact", result); err != nil {
+			return result, err
+		}
+	}
+	for _, pa := range automations {
+		if err := create(ctx, c, opts, pa, "ProvisionAutomation", result); err != nil {
+			return result, err
+		}
+	}
+	for _, bc := range configs {
+		if err := create(ctx, c, opts, bc, "BootConfig", result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// readEntry reads the next archive entry, which must be name.
+func readEntry(tr *tar.Reader, name string) ([]byte, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if hdr.Name != name {
+		return nil, fmt.Errorf("expected %s in bundle, found %s", name, hdr.Name)
+	}
+	if hdr.Size > maxMetadataSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", name, maxMetadataSize)
+	}
+	return io.ReadAll(tr)
+}
+
+// decodeResources parses resources.yaml. Only the kinds Export writes are
+// accepted.
+func decodeResources(data []byte) (
+	[]*isobootgithubiov1alpha1.BootArtifact,
+	[]*isobootgithubiov1alpha1.ProvisionAutomation,
+	[]*isobootgithubiov1alpha1.BootConfig,
+	error,
+) {
+	scheme := runtime.NewScheme()
+	if err := isobootgithubiov1alpha1.AddToScheme(scheme); err != nil {
+		return nil, nil, nil, err
+	}
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+
+	var (
+		artifacts   []*isobootgithubiov1alpha1.BootArtifact
+		automations []*isobootgithubiov1alpha1.ProvisionAutomation
+		configs     []*isobootgithubiov1alpha1.BootConfig
+	)
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("reading %s: %w", resourcesName, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, _, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("decoding %s: %w", resourcesName, err)
+		}
+		switch o := obj.(type) {
+		case *isobootgithubiov1alpha1.BootArtifact:
+			artifacts = append(artifacts, o)
+		case *isobootgithubiov1alpha1.ProvisionAutomation:
+			automations = append(automations, o)
+		case *isobootgithubiov1alpha1.BootConfig:
+			configs = append(configs, o)
+		default:
+			return nil, nil, nil, fmt.Errorf("unexpected %s in %s", obj.GetObjectKind().GroupVersionKind().Kind, resourcesName)
+		}
+	}
+	return artifacts, automations, configs, nil
+}
+
+// importArtifactFile writes the file of entry under root, verifying its
+// digest before it replaces any file already there.
+func importArtifactFile(root *os.Root, entry ArtifactFile, r io.Reader) error {
+	h, want, err := newHash(entry.Digest)
+	if err != nil {
+		return err
+	}
+	if err := root.MkdirAll(entry.Name, 0o755); err != nil {
+		return err
+	}
+	name := path.Join(entry.Name, path.Base(entry.Path))
+	tmp := name + ".import"
+	f, err := root.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(io.MultiWriter(f, h), r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); err == nil && got != want {
+		err = fmt.Errorf("digest mismatch: expected %s, got %s", want, got)
+	}
+	if err != nil {
+		_ = root.Remove(tmp)
+		return err
+	}
+	return root.Rename(tmp, name)
+}
+
+// create creates obj, in opts.Namespace when set, and records it in result.
+// An object that already exists is recorded as skipped.
+func create(ctx context.Context, c client.Client, opts ImportOptions, obj client.Object, kind string, result *ImportResult) error {
+	if opts.Namespace != "" {
+		obj.SetNamespace(opts.Namespace)
+	}
+	err := c.Create(ctx, obj)
+	switch {
+	case apierrors.IsAlreadyExists(err):
+		result.Skipped = append(result.Skipped, describe(kind, obj))
+		return nil
+	case err != nil:
+		return fmt.Errorf("creating %s: %w", describe(kind, obj), err)
+	}
+	result.Created = append(result.Created, describe(kind, obj))
+	return nil
+}
+
+func describe(kind string, obj client.Object) string {
+	return kind + " " + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// annotateDigests records the digests a BootArtifact had in the exporting
+// cluster, so the controller can verify the imported file without resolving
+// spec.checksums or an OCI tag, which may not be reachable. They travel as
+// an annotation because the controller may reconcile the artifact as soon
+// as it is created, before a status update could be made.
+func annotateDigests(a *isobootgithubiov1alpha1.BootArtifact, entry ArtifactFile) {
+	if entry.ResolvedDigest == "" && entry.DecompressedDigest == "" {
+		return
+	}
+	data, _ := json.Marshal(struct {
+		ResolvedDigest     string `json:"resolvedDigest,omitempty"`
+		DecompressedDigest string `json:"decompressedDigest,omitempty"`
+	}{entry.ResolvedDigest, entry.DecompressedDigest})
+	if a.Annotations == nil {
+		a.Annotations = map[string]string{}
+	}
+	a.Annotations[isobootgithubiov1alpha1.BootArtifactAnnotationImportedDigests] = string(data)
+}
@@ -0,0 +1,383 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+	"github.com/isoboot/isoboot/internal/controller"
+)
+
+var (
+	kernelContent = []byte("bundled kernel")
+	initrdContent = []byte("bundled initrd")
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	sch := runtime.NewScheme()
+	if err := isobootgithubiov1alpha1.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	return sch
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sourceCluster returns a client holding a BootConfig labelled site=a that
+// uses a pinned kernel and an initrd resolved from signed checksums, an
+// unrelated BootArtifact, and the data directory with their files.
+func sourceCluster(t *testing.T) (client.Client, string) {
+	t.Helper()
+	kernelSum := sha256.Sum256(kernelContent)
+	initrdSum := sha512.Sum512(initrdContent)
+	ready := func(name, url string) *isobootgithubiov1alpha1.BootArtifact {
+		return &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 1, ResourceVersion: "7"},
+			Spec:       isobootgithubiov1alpha1.BootArtifactSpec{URL: url},
+			Status: isobootgithubiov1alpha1.BootArtifactStatus{
+				Phase: isobootgithubiov1alpha1.BootArtifactPhaseReady, ObservedGeneration: 1,
+			},
+		}
+	}
+	kernel := ready("kernel", "https://example.com/debian/vmlinuz")
+	kernel.Spec.SHA256 = new(hex.EncodeToString(kernelSum[:]))
+	initrd := ready("initrd", "https://example.com/debian/initrd.gz")
+	initrd.Spec.Checksums = &isobootgithubiov1alpha1.BootArtifactChecksums{
+		URL: "https://example.com/SHA512SUMS", SignatureURL: "https://example.com/SHA512SUMS.gpg",
+		Keyring: isobootgithubiov1alpha1.ConfigMapKeyReference{Name: "keys", Key: "keys.asc"},
+	}
+	initrd.Status.ResolvedDigest = "sha512:" + hex.EncodeToString(initrdSum[:])
+	other := ready("other", "https://example.com/other/vmlinuz")
+	other.Spec.SHA256 = new(hex.EncodeToString(kernelSum[:]))
+
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(
+		kernel, initrd, other,
+		&isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "debian", Namespace: "default", Labels: map[string]string{"site": "a"}},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				Netboot: &isobootgithubiov1alpha1.BootConfigNetbootSpec{KernelRef: "kernel", InitrdRef: "initrd"},
+			},
+		},
+		&isobootgithubiov1alpha1.ProvisionAutomation{
+			ObjectMeta: metav1.ObjectMeta{Name: "preseed", Namespace: "default", Labels: map[string]string{"site": "a"}},
+			Spec:       isobootgithubiov1alpha1.ProvisionAutomationSpec{Files: map[string]string{"preseed.cfg": "d-i"}},
+		},
+	).Build()
+
+	dataDir := t.TempDir()
+	writeFile(t, filepath.Join(dataDir, "artifacts", "kernel", "vmlinuz"), kernelContent)
+	writeFile(t, filepath.Join(dataDir, "artifacts", "initrd", "initrd.gz"), initrdContent)
+	return c, dataDir
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	src, srcDir := sourceCluster(t)
+
+	var buf bytes.Buffer
+	manifest, err := Export(ctx, src, ExportOptions{
+		Namespace: "default", Selector: labels.SelectorFromSet(labels.Set{"site": "a"}), DataDir: srcDir,
+	}, &buf)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(manifest.Artifacts) != 2 || manifest.Artifacts[0].Name != "initrd" || manifest.Artifacts[1].Name != "kernel" {
+		t.Fatalf("manifest artifacts = %+v, want initrd and kernel", manifest.Artifacts)
+	}
+
+	dst := fake.NewClientBuilder().WithScheme(newScheme(t)).
+		WithStatusSubresource(&isobootgithubiov1alpha1.BootArtifact{}).Build()
+	dstDir := t.TempDir()
+	result, err := Import(ctx, dst, ImportOptions{DataDir: dstDir, Namespace: "site-a"}, &buf)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(result.Created) != 4 || len(result.Skipped) != 0 {
+		t.Fatalf("result = %+v, want 4 created", result)
+	}
+
+	for path, want := range map[string][]byte{
+		filepath.Join(dstDir, "artifacts", "kernel", "vmlinuz"):   kernelContent,
+		filepath.Join(dstDir, "artifacts", "initrd", "initrd.gz"): initrdContent,
+	} {
+		got, err := os.ReadFile(path)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s = %q, %v; want %q", path, got, err, want)
+		}
+	}
+	var initrd isobootgithubiov1alpha1.BootArtifact
+	if err := dst.Get(ctx, client.ObjectKey{Namespace: "site-a", Name: "initrd"}, &initrd); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"resolvedDigest":"` + manifest.Artifacts[0].ResolvedDigest + `"}`
+	if got := initrd.Annotations[isobootgithubiov1alpha1.BootArtifactAnnotationImportedDigests]; got != want {
+		t.Errorf("initrd imported digests = %s, want %s", got, want)
+	}
+	if initrd.Status.Phase != "" {
+		t.Errorf("initrd phase = %q, want it left to the controller", initrd.Status.Phase)
+	}
+	if err := dst.Get(ctx, client.ObjectKey{Namespace: "site-a", Name: "other"}, &initrd); err == nil {
+		t.Error("unselected BootArtifact other was imported")
+	}
+	var bc isobootgithubiov1alpha1.BootConfig
+	if err := dst.Get(ctx, client.ObjectKey{Namespace: "site-a", Name: "debian"}, &bc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Importing again leaves the existing objects alone.
+	buf.Reset()
+	if _, err := Export(ctx, src, ExportOptions{
+		Namespace: "default", Selector: labels.SelectorFromSet(labels.Set{"site": "a"}), DataDir: srcDir,
+	}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	result, err = Import(ctx, dst, ImportOptions{DataDir: dstDir, Namespace: "site-a"}, &buf)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(result.Created) != 0 || len(result.Skipped) != 4 {
+		t.Fatalf("result = %+v, want 4 skipped", result)
+	}
+}
+
+func TestExportRequiresFiles(t *testing.T) {
+	src, srcDir := sourceCluster(t)
+	// BootArtifact other is Ready but its file is not on disk.
+	_, err := Export(context.Background(), src, ExportOptions{Namespace: "default", Selector: labels.Everything(), DataDir: srcDir}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "BootArtifact other") {
+		t.Fatalf("Export error = %v, want missing file of other", err)
+	}
+}
+
+func TestExportRejectsCorruptFile(t *testing.T) {
+	ctx := context.Background()
+	src, srcDir := sourceCluster(t)
+	writeFile(t, filepath.Join(srcDir, "artifacts", "kernel", "vmlinuz"), []byte("tampered kernel"))
+
+	_, err := Export(ctx, src, ExportOptions{
+		Namespace: "default", Selector: labels.SelectorFromSet(labels.Set{"site": "a"}), DataDir: srcDir,
+	}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("Export error = %v, want digest mismatch", err)
+	}
+}
+
+func TestImportRejectsUnlistedFile(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	manifest, _ := json.Marshal(Manifest{Version: ManifestVersion})
+	for _, e := range []struct {
+		name string
+		data []byte
+	}{
+		{manifestName, manifest},
+		{resourcesName, nil},
+		{"artifacts/../../etc/cron.d/evil", []byte("* * * * * root true")},
+	} {
+		if err := writeEntry(tw, e.name, e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).Build()
+	dataDir := t.TempDir()
+	_, err := Import(context.Background(), c, ImportOptions{DataDir: dataDir}, &buf)
+	if err == nil || !strings.Contains(err.Error(), "unexpected entry") {
+		t.Fatalf("Import error = %v, want unexpected entry", err)
+	}
+}
+
+func TestImportRejectsDigestMismatch(t *testing.T) {
+	ctx := context.Background()
+	src, srcDir := sourceCluster(t)
+	var buf bytes.Buffer
+	if _, err := Export(ctx, src, ExportOptions{
+		Namespace: "default", Selector: labels.SelectorFromSet(labels.Set{"site": "a"}), DataDir: srcDir,
+	}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Replace(buf.Bytes(), kernelContent, []byte("tampered kerne"), 1)
+
+	dst := fake.NewClientBuilder().WithScheme(newScheme(t)).Build()
+	dstDir := t.TempDir()
+	_, err := Import(ctx, dst, ImportOptions{DataDir: dstDir}, bytes.NewReader(tampered))
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("Import error = %v, want digest mismatch", err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "artifacts", "kernel", "vmlinuz")); !os.IsNotExist(err) {
+		t.Errorf("tampered file was written: %v", err)
+	}
+	var list isobootgithubiov1alpha1.BootArtifactList
+	if err := dst.List(ctx, &list); err != nil || len(list.Items) != 0 {
+		t.Errorf("objects created despite a bad file: %v, %v", list.Items, err)
+	}
+}
+
+// exportSiteA returns a bundle of the objects labelled site=a.
+func exportSiteA(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	src, srcDir := sourceCluster(t)
+	var buf bytes.Buffer
+	if _, err := Export(context.Background(), src, ExportOptions{
+		Namespace: "default", Selector: labels.SelectorFromSet(labels.Set{"site": "a"}), DataDir: srcDir,
+	}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestImportKeepsFilesOfExistingArtifacts(t *testing.T) {
+	ctx := context.Background()
+	dst := fake.NewClientBuilder().WithScheme(newScheme(t)).
+		WithStatusSubresource(&isobootgithubiov1alpha1.BootArtifact{}).
+		WithObjects(&isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: "kernel", Namespace: "site-a"},
+			Spec:       isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/site-a/vmlinuz"},
+		}).Build()
+	dstDir := t.TempDir()
+	existing := []byte("site-a kernel")
+	writeFile(t, filepath.Join(dstDir, "artifacts", "kernel", "vmlinuz"), existing)
+
+	result, err := Import(ctx, dst, ImportOptions{DataDir: dstDir, Namespace: "site-a"}, exportSiteA(t))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(result.Created) != 3 || len(result.Skipped) != 1 || result.Skipped[0] != "BootArtifact site-a/kernel" {
+		t.Fatalf("result = %+v, want kernel skipped", result)
+	}
+	if got, err := os.ReadFile(filepath.Join(dstDir, "artifacts", "kernel", "vmlinuz")); err != nil || !bytes.Equal(got, existing) {
+		t.Errorf("kernel file = %q, %v; want it left alone", got, err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dstDir, "artifacts", "initrd", "initrd.gz")); err != nil || !bytes.Equal(got, initrdContent) {
+		t.Errorf("initrd file = %q, %v; want the bundled file", got, err)
+	}
+}
+
+func TestImportRefusesNameTakenInOtherNamespace(t *testing.T) {
+	ctx := context.Background()
+	dst := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(&isobootgithubiov1alpha1.BootArtifact{
+		ObjectMeta: metav1.ObjectMeta{Name: "kernel", Namespace: "site-b"},
+		Spec:       isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/site-b/vmlinuz"},
+	}).Build()
+	dstDir := t.TempDir()
+	existing := []byte("site-b kernel")
+	writeFile(t, filepath.Join(dstDir, "artifacts", "kernel", "vmlinuz"), existing)
+
+	_, err := Import(ctx, dst, ImportOptions{DataDir: dstDir, Namespace: "site-a"}, exportSiteA(t))
+	if err == nil || !strings.Contains(err.Error(), "BootArtifact site-b/kernel") {
+		t.Fatalf("Import error = %v, want the file of site-b/kernel refused", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dstDir, "artifacts", "kernel", "vmlinuz")); err != nil || !bytes.Equal(got, existing) {
+		t.Errorf("kernel file = %q, %v; want it left alone", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "artifacts", "initrd")); !os.IsNotExist(err) {
+		t.Errorf("initrd was written despite the refused bundle: %v", err)
+	}
+	var list isobootgithubiov1alpha1.BootArtifactList
+	if err := dst.List(ctx, &list); err != nil || len(list.Items) != 1 {
+		t.Errorf("objects created despite the refused bundle: %v, %v", list.Items, err)
+	}
+}
+
+func TestImportedArtifactsReadyWithoutDownload(t *testing.T) {
+	ctx := context.Background()
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		http.Error(w, "air-gapped", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// The controller reconciles each BootArtifact as soon as it is created.
+	dstDir := t.TempDir()
+	var reconcileErrs []error
+	dst := fake.NewClientBuilder().WithScheme(newScheme(t)).
+		WithStatusSubresource(&isobootgithubiov1alpha1.BootArtifact{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if err := c.Create(ctx, obj, opts...); err != nil {
+					return err
+				}
+				if _, ok := obj.(*isobootgithubiov1alpha1.BootArtifact); ok {
+					r := &controller.BootArtifactReconciler{
+						Client: c, Scheme: c.Scheme(), DataDir: dstDir,
+						HTTPClient: server.Client(), Scheduler: controller.NewDownloadScheduler(1, 0),
+					}
+					_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+					reconcileErrs = append(reconcileErrs, err)
+				}
+				return nil
+			},
+		}).Build()
+
+	if _, err := Import(ctx, dst, ImportOptions{DataDir: dstDir, Namespace: "site-a"}, exportSiteA(t)); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if err := errors.Join(reconcileErrs...); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	for _, name := range []string{"initrd", "kernel"} {
+		var a isobootgithubiov1alpha1.BootArtifact
+		if err := dst.Get(ctx, client.ObjectKey{Namespace: "site-a", Name: name}, &a); err != nil {
+			t.Fatal(err)
+		}
+		if a.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady {
+			t.Errorf("%s phase = %q (%s), want Ready", name, a.Status.Phase, a.Status.Message)
+		}
+		if _, ok := a.Annotations[isobootgithubiov1alpha1.BootArtifactAnnotationImportedDigests]; ok {
+			t.Errorf("%s still has the imported digests annotation", name)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("%d requests to the source, want none", n)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// reconcileArchive handles archive mode: extract the kernel, initrd and any
+// extra files from a tar or zip artifact into the boot directory.
+func (r *BootConfigReconciler) reconcileArchive(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig) (ctrl.Result, error) {
+	a := bc.Spec.Archive
+	return r.reconcileExtraction(ctx, bc, extraction{
+		kind: "archive", artifactRef: a.ArtifactRef,
+		kernelPath: a.KernelPath, initrdPath: a.InitrdPath, extraFiles: a.ExtraFiles,
+		extract: func(archivePath, bootDir string, files []isoFile, current bool) error {
+			format, err := archiveFormat(a.Format, filepath.Base(archivePath))
+			if err != nil {
+				return err
+			}
+			return extractFromArchive(archivePath, format, bootDir, files, current)
+		},
+	})
+}
+
+// archiveFormat returns format, or the format named by the extension of the
+// archive's filename when format is empty.
+func archiveFormat(format isobootgithubiov1alpha1.BootConfigArchiveFormat, filename string) (isobootgithubiov1alpha1.BootConfigArchiveFormat, error) {
+	if format != "" {
+		return format, nil
+	}
+	switch name := strings.ToLower(filename); {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return isobootgithubiov1alpha1.BootConfigArchiveFormatTarGz, nil
+	case strings.HasSuffix(name, ".tar"):
+		return isobootgithubiov1alpha1.BootConfigArchiveFormatTar, nil
+	case strings.HasSuffix(name, ".zip"):
+		return isobootgithubiov1alpha1.BootConfigArchiveFormatZip, nil
+	}
+	return "", fmt.Errorf("cannot infer the archive format of %q; set spec.archive.format", filename)
+}
+
+// extractFromArchive writes the archive members named by files into
+// outputDir. When current is set and every output exists, the archive is
+// not read at all. Only regular files are extracted; member names are
+// matched after cleaning, so "./linux" and "linux" are the same member, and
+// never used as output paths.
+func extractFromArchive(archivePath string, format isobootgithubiov1alpha1.BootConfigArchiveFormat, outputDir string, files []isoFile, current bool) error {
+	if current && !slices.ContainsFunc(files, func(f isoFile) bool {
+		return !extracted(filepath.Join(outputDir, filepath.FromSlash(f.name)))
+	}) {
+		return nil // already extracted and current
+	}
+
+	wanted := map[string][]string{}
+	for _, f := range files {
+		src := cleanArchivePath(f.src)
+		dst := filepath.Join(outputDir, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return fmt.Errorf("creating directory for %q: %w", f.name, err)
+		}
+		wanted[src] = append(wanted[src], dst)
+	}
+
+	// extract writes a wanted member to its outputs and stops the walk once
+	// none are left.
+	extract := func(name string, regular bool, size int64, open func() (io.ReadCloser, error)) error {
+		name = cleanArchivePath(name)
+		dsts, ok := wanted[name]
+		if !ok {
+			return nil
+		}
+		delete(wanted, name)
+		if !regular {
+			return fmt.Errorf("%q in archive is not a regular file", name)
+		}
+		if size > maxExtractedFileSize {
+			return fmt.Errorf("file %q exceeds max extract size %d bytes", name, int64(maxExtractedFileSize))
+		}
+		rc, err := open()
+		if err != nil {
+			return fmt.Errorf("opening %q in archive: %w", name, err)
+		}
+		err = writeExtracted(rc, name, dsts[0])
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+		// A member wanted under several names is copied from the first.
+		for _, dst := range dsts[1:] {
+			if err := copyExtracted(dsts[0], name, dst); err != nil {
+				return err
+			}
+		}
+		if len(wanted) == 0 {
+			return errArchiveDone
+		}
+		return nil
+	}
+
+	var err error
+	switch format {
+	case isobootgithubiov1alpha1.BootConfigArchiveFormatZip:
+		err = walkZip(archivePath, extract)
+	default:
+		err = walkTar(archivePath, format == isobootgithubiov1alpha1.BootConfigArchiveFormatTarGz, extract)
+	}
+	if err != nil && !errors.Is(err, errArchiveDone) {
+		return err
+	}
+	for _, f := range files {
+		if _, missing := wanted[cleanArchivePath(f.src)]; missing {
+			return fmt.Errorf("%q not found in archive", f.src)
+		}
+	}
+	return nil
+}
+
+// errArchiveDone stops an archive walk once every wanted member is found.
+var errArchiveDone = errors.New("all members extracted")
+
+// archiveVisitor is called by walkTar and walkZip for each archive member.
+// open returns the member's contents and may be called once.
+type archiveVisitor func(name string, regular bool, size int64, open func() (io.ReadCloser, error)) error
+
+// walkTar passes each member of the tar archive at archivePath to visit.
+func walkTar(archivePath string, gzipped bool, visit archiveVisitor) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	var src io.Reader = f
+	if gzipped {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("reading gzip: %w", err)
+		}
+		defer func() { _ = zr.Close() }()
+		src = zr
+	}
+
+	tr := tar.NewReader(src)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar: %w", err)
+		}
+		if err := visit(hdr.Name, hdr.Typeflag == tar.TypeReg, hdr.Size, func() (io.ReadCloser, error) {
+			return io.NopCloser(tr), nil
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// walkZip passes each member of the zip archive at archivePath to visit.
+func walkZip(archivePath string, visit archiveVisitor) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("opening zip: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	for _, f := range zr.File {
+		if err := visit(f.Name, f.Mode().IsRegular(), int64(f.UncompressedSize64), f.Open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyExtracted copies an extracted file to dst atomically.
+func copyExtracted(src, name, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("opening %q: %w", src, err)
+	}
+	defer func() { _ = f.Close() }()
+	return writeExtracted(f, name, dst)
+}
+
+// cleanArchivePath returns an archive member path without a leading "./"
+// or "/", so spec paths match however the archive was created.
+func cleanArchivePath(p string) string {
+	return path.Clean(strings.TrimLeft(p, "/"))
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Artifact bytes are stored once per digest under DataDir/blobs/<algorithm>/<hex>.
+// Each artifacts/<name>/<file> is a hardlink to its blob, so BootArtifacts
+// with the same digest share one copy on disk and the existing paths keep
+// working for BootConfigs and httpd. A blob whose link count has dropped to
+// one is referenced by no artifact and is pruned.
+
+// blobPath returns the content-addressed path for d.
+func (r *BootArtifactReconciler) blobPath(d digest) string {
+	return filepath.Join(r.DataDir, "blobs", d.algorithm, strings.ToLower(d.hex))
+}
+
+// commitBlob moves the verified download at tmpPath to filePath and records
+// it in the blob store. If the blob already exists, filePath is linked to it
+// instead and the duplicate download is discarded.
+func (r *BootArtifactReconciler) commitBlob(tmpPath, filePath string, want digest) error {
+	blob := r.blobPath(want)
+	if err := os.MkdirAll(filepath.Dir(blob), 0o755); err != nil {
+		return fmt.Errorf("creating blob directory: %w", err)
+	}
+
+	err := os.Link(tmpPath, blob)
+	switch {
+	case err == nil:
+		// tmpPath and the blob now share an inode; renaming tmpPath into place
+		// keeps the link count at two or more throughout, so pruneBlobs never
+		// sees the blob as unreferenced.
+		if err := os.Rename(tmpPath, filePath); err != nil {
+			return fmt.Errorf("renaming file: %w", err)
+		}
+	case os.IsExist(err):
+		if err := linkFile(blob, filePath); err != nil {
+			return fmt.Errorf("linking stored blob: %w", err)
+		}
+		_ = os.Remove(tmpPath)
+	default:
+		return fmt.Errorf("storing blob: %w", err)
+	}
+
+	// Directory fsync for crash durability
+	if err := syncDir(filepath.Dir(filePath)); err != nil {
+		return fmt.Errorf("syncing directory: %w", err)
+	}
+	if err := syncDir(filepath.Dir(blob)); err != nil {
+		return fmt.Errorf("syncing blob directory: %w", err)
+	}
+	return nil
+}
+
+// linkStoredBlob links filePath to an existing blob for want, so an artifact
+// whose bytes are already on disk is not downloaded again. It returns false
+// when there is no such blob. A blob that fails verification is removed.
+func (r *BootArtifactReconciler) linkStoredBlob(ctx context.Context, want digest, filePath string) (bool, error) {
+	log := logf.FromContext(ctx)
+	blob := r.blobPath(want)
+
+	computedHash, err := hashFile(blob, want)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("hashing blob: %w", err)
+	}
+	if !strings.EqualFold(computedHash, want.hex) {
+		log.Info("Stored blob is corrupt, removing", "path", blob, "got", computedHash)
+		hashMismatches.WithLabelValues("verify").Inc()
+		if err := os.Remove(blob); err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("removing corrupt blob: %w", err)
+		}
+		return false, nil
+	}
+
+	if err := linkFile(blob, filePath); err != nil {
+		return false, err
+	}
+	if err := syncDir(filepath.Dir(filePath)); err != nil {
+		return false, fmt.Errorf("syncing directory: %w", err)
+	}
+	return true, nil
+}
+
+// adoptBlob records an already verified artifact file in the blob store if
+// the blob is missing, e.g. for files downloaded before the store existed.
+func (r *BootArtifactReconciler) adoptBlob(ctx context.Context, filePath string, want digest) {
+	blob := r.blobPath(want)
+	if _, err := os.Lstat(blob); err == nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(blob), 0o755); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to create blob directory", "path", blob)
+		return
+	}
+	if err := os.Link(filePath, blob); err != nil && !os.IsExist(err) {
+		logf.FromContext(ctx).Error(err, "Failed to add artifact to blob store", "path", filePath)
+	}
+}
+
+// pruneBlobs removes blobs that no artifact file links to any more.
+func (r *BootArtifactReconciler) pruneBlobs(ctx context.Context) {
+	log := logf.FromContext(ctx)
+	dirs, err := filepath.Glob(filepath.Join(r.DataDir, "blobs", "*"))
+	if err != nil {
+		return
+	}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			log.Error(err, "Failed to list blob directory", "path", dir)
+			continue
+		}
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			st, ok := info.Sys().(*syscall.Stat_t)
+			if !ok || st.Nlink > 1 {
+				continue
+			}
+			blob := filepath.Join(dir, e.Name())
+			if err := os.Remove(blob); err != nil && !os.IsNotExist(err) {
+				log.Error(err, "Failed to remove unreferenced blob", "path", blob)
+				continue
+			}
+			log.Info("Removed unreferenced blob", "path", blob)
+		}
+	}
+}
+
+// linkFile atomically makes path a hardlink to blob, replacing any existing
+// file at path.
+func linkFile(blob, path string) error {
+	tmp := path + ".link"
+	_ = os.Remove(tmp)
+	if err := os.Link(blob, tmp); err != nil {
+		return err
+	}
+	err := os.Rename(tmp, path)
+	// rename(2) is a no-op that leaves tmp behind when path already links
+	// to the same inode.
+	_ = os.Remove(tmp)
+	return err
+}
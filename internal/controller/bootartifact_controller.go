@@ -17,30 +17,55 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
-	"crypto/sha512"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"hash"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
 	"github.com/isoboot/isoboot/internal/urlutil"
 )
 
+// errHashMismatch is wrapped by the error for a download that does not match
+// the expected digest.
+var errHashMismatch = errors.New("hash mismatch")
+
+// bootArtifactFinalizer makes deletion of a BootArtifact wait until its
+// directory under DataDir/artifacts has been removed.
+const bootArtifactFinalizer = "isoboot.github.io/artifact-files"
+
+// queuedRequeueInterval is how often a Queued artifact re-checks for a
+// download slot in case its scheduler wakeup was dropped. It also keeps the
+// artifact's queue entry from expiring.
+const queuedRequeueInterval = 30 * time.Second
+
+// unlimitedDownloadWorkers is the number of concurrent reconciles used when
+// the scheduler places no limit on concurrent downloads.
+const unlimitedDownloadWorkers = 8
+
 // BootArtifactReconciler reconciles a BootArtifact object
 type BootArtifactReconciler struct {
 	client.Client
@@ -48,12 +73,36 @@ type BootArtifactReconciler struct {
 	DataDir    string
 	HTTPClient *http.Client
 	Recorder   events.EventRecorder
+	// Scheduler limits concurrent downloads and their bandwidth; nil means
+	// unlimited.
+	Scheduler *DownloadScheduler
+	// ProgressInterval is the minimum time between download progress status
+	// updates; zero means defaultProgressInterval.
+	ProgressInterval time.Duration
+	// VerifyInterval is how often a Ready artifact's file is re-hashed to
+	// detect bit rot or tampering; zero disables periodic verification.
+	VerifyInterval time.Duration
+	// MinFreeSpace is the number of bytes a download must leave free in
+	// DataDir; downloads that would not fit are refused before they start.
+	MinFreeSpace int64
+	// EvictUnreferenced allows removing the files of artifacts no BootConfig
+	// references, least recently accessed first, to make room for a download.
+	EvictUnreferenced bool
+	// FileSourceRoot is the absolute directory file:// sources are served
+	// from; empty disables file:// sources.
+	FileSourceRoot string
+
+	sourceClients sourceClients
 }
 
 // +kubebuilder:rbac:groups=isoboot.github.io,resources=bootartifacts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=isoboot.github.io,resources=bootartifacts/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=isoboot.github.io,resources=bootartifacts/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups=isoboot.github.io,resources=bootconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=isoboot.github.io,resources=artifactmirrorpolicies,verbs=get;list;watch
 
 func (r *BootArtifactReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	var artifact isobootgithubiov1alpha1.BootArtifact
@@ -61,58 +110,143 @@ func (r *BootArtifactReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if !artifact.DeletionTimestamp.IsZero() {
+		r.Scheduler.Forget(req.NamespacedName)
+		r.forgetSourceClient(req.NamespacedName)
+		return ctrl.Result{}, r.finalize(ctx, &artifact)
+	}
+	if controllerutil.AddFinalizer(&artifact, bootArtifactFinalizer) {
+		if err := r.Update(ctx, &artifact); err != nil {
+			return ctrl.Result{}, fmt.Errorf("adding finalizer: %w", err)
+		}
+	}
+
+	if err := r.adoptImportedDigests(ctx, &artifact); err != nil {
+		return ctrl.Result{}, err
+	}
+	if hash := fileSpecHash(&artifact); artifact.Status.FileSpecHash != hash {
+		// The file changed since the file on disk was verified; a digest
+		// resolved from the old spec.checksums, or of a file decompressed
+		// from the old source, no longer applies. Status written before
+		// fileSpecHash existed only tells that the generation changed.
+		if artifact.Status.FileSpecHash != "" || artifact.Status.ObservedGeneration != artifact.Generation {
+			artifact.Status.ResolvedDigest = ""
+			artifact.Status.DecompressedDigest = ""
+		}
+		artifact.Status.FileSpecHash = hash
+	}
+
 	filePath := r.filePath(&artifact)
 
 	// Check if file exists on disk and verify hash
 	if _, err := os.Stat(filePath); err == nil {
-		ok, err := r.verifyExisting(ctx, &artifact, filePath)
+		want, err := r.expectedDigest(ctx, &artifact, false)
 		if err != nil {
-			return ctrl.Result{}, err
+			return r.setFailure(ctx, &artifact, failureReasonDigest, fmt.Sprintf("resolving digest: %v", err))
 		}
-		if ok {
-			return ctrl.Result{}, nil
+		// A decompressed file whose digest is not recorded yet cannot be
+		// verified; download it again.
+		if served, known := servedDigest(&artifact, want); known {
+			ok, err := r.verifyExisting(ctx, &artifact, filePath, want, served)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if ok {
+				if driftCheckDue(&artifact) {
+					if err := r.checkDrift(ctx, &artifact); err != nil {
+						return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+					}
+				}
+				return ctrl.Result{RequeueAfter: r.nextCheck(&artifact)}, nil
+			}
 		}
-		// Hash mismatch — file was removed, fall through to download
+		// Hash mismatch — fall through to download, which replaces the file
 	} else if !os.IsNotExist(err) {
-		return r.setFailure(ctx, &artifact, fmt.Sprintf("stat file: %v", err))
+		return r.setFailure(ctx, &artifact, failureReasonFilesystem, fmt.Sprintf("stat file: %v", err))
+	}
+
+	// An evicted artifact stays off disk until a BootConfig needs it again.
+	if artifact.Status.Phase == isobootgithubiov1alpha1.BootArtifactPhaseEvicted &&
+		artifact.Status.ObservedGeneration == artifact.Generation {
+		referenced, err := r.isReferenced(ctx, &artifact)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("listing BootConfigs: %w", err)
+		}
+		if !referenced {
+			return ctrl.Result{}, nil
+		}
 	}
 
 	return r.download(ctx, &artifact, filePath)
 }
 
 // verifyExisting checks the hash of an existing file. Returns (true, nil) if
-// the file is valid, or (false, nil) if the hash mismatched and the file was
-// removed (caller should proceed to download).
-func (r *BootArtifactReconciler) verifyExisting(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, filePath string) (bool, error) {
+// the file is valid, or (false, nil) if the hash mismatched (caller should
+// proceed to download). A file that no longer matches an unchanged spec is
+// corrupt and removed; after a spec change the old file is left in place
+// until the download atomically replaces it. Hashing is skipped when the
+// file's verification record still matches, unless a periodic verification
+// is due. want is the digest of the download and served that of the file on
+// disk; they differ only for spec.decompress.
+func (r *BootArtifactReconciler) verifyExisting(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, filePath string, want, served digest) (bool, error) {
 	log := logf.FromContext(ctx)
 
-	computedHash, err := hashFile(filePath, artifact.Spec.SHA256 != nil)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// File deleted between Stat and Open, fall through to download
-			return false, nil
+	cached := !r.verificationDue(artifact) && hasVerifiedRecord(filePath, served)
+	computedHash := served.hex
+	if !cached {
+		var err error
+		computedHash, err = hashFile(filePath, served)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// File deleted between Stat and Open, fall through to download
+				return false, nil
+			}
+			return false, fmt.Errorf("hashing existing file: %w", err)
 		}
-		return false, fmt.Errorf("hashing existing file: %w", err)
 	}
 
-	expectedHash := expectedHash(artifact)
+	expectedHash := served.hex
 	if !strings.EqualFold(computedHash, expectedHash) {
+		if artifact.Status.ObservedGeneration != artifact.Generation {
+			log.Info("Artifact spec changed, re-downloading", "path", filePath)
+			return false, nil
+		}
 		log.Info("Hash mismatch for existing file, removing", "expected", expectedHash, "got", computedHash)
+		hashMismatches.WithLabelValues("verify").Inc()
 		if r.Recorder != nil {
-			r.Recorder.Eventf(artifact, nil, "Warning", "HashMismatch", "VerifyExisting",
-				"Existing file hash mismatch: expected %s got %s, re-downloading", expectedHash, computedHash)
+			r.Recorder.Eventf(artifact, nil, "Warning", "Corrupted", "VerifyExisting",
+				"Stored file hash mismatch: expected %s got %s, re-downloading", expectedHash, computedHash)
 		}
 		if err := os.Remove(filePath); err != nil {
 			log.Info("Could not remove mismatched file, will overwrite via download", "path", filePath, "error", err)
 		}
+		// Leave Ready right away so dependent BootConfigs stop serving the
+		// file while it is downloaded again.
+		if artifact.Status.Phase == isobootgithubiov1alpha1.BootArtifactPhaseReady {
+			artifact.Status.Phase = isobootgithubiov1alpha1.BootArtifactPhasePending
+			artifact.Status.Message = "Stored file is corrupted, re-downloading"
+			if err := r.updateStatus(ctx, artifact); err != nil {
+				return false, fmt.Errorf("updating status: %w", err)
+			}
+		}
 		return false, nil
 	}
 
-	log.Info("Artifact already on disk, skipping download", "path", filePath)
+	log.Info("Artifact already on disk, skipping download", "path", filePath, "cached", cached)
+	if !cached {
+		recordVerified(ctx, filePath, served)
+	}
+	r.adoptBlob(ctx, filePath, served)
+	r.removeStaleFiles(ctx, filePath)
 
 	// Skip status write if already Ready — avoids a no-op update on
-	// every controller restart for stable artifacts.
-	if artifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady {
+	// every controller restart for stable artifacts — unless a periodic
+	// verification is due and lastChecked needs to move forward.
+	if artifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady ||
+		r.verificationDue(artifact) ||
+		artifact.Status.ObservedGeneration != artifact.Generation ||
+		artifact.Status.ResolvedDigest != resolvedDigest(artifact, want) {
+		artifact.Status.ResolvedDigest = resolvedDigest(artifact, want)
 		if err := r.setReady(ctx, artifact); err != nil {
 			return false, err
 		}
@@ -124,10 +258,53 @@ func (r *BootArtifactReconciler) verifyExisting(ctx context.Context, artifact *i
 func (r *BootArtifactReconciler) download(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, filePath string) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
+	// Always resolve signed checksums or OCI tags afresh before downloading,
+	// so a republished manifest is picked up rather than a stale cached digest.
+	want, err := r.expectedDigest(ctx, artifact, true)
+	if err != nil {
+		return r.setFailure(ctx, artifact, failureReasonDigest, fmt.Sprintf("resolving digest: %v", err))
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return r.setFailure(ctx, artifact, failureReasonFilesystem, fmt.Sprintf("creating directory: %v", err))
+	}
+
+	// Another BootArtifact may already have stored the same bytes. The
+	// digest of a decompressed file is only known after downloading it.
+	reused := false
+	if artifact.Spec.Decompress == "" {
+		if reused, err = r.linkStoredBlob(ctx, want, filePath); err != nil {
+			return r.setFailure(ctx, artifact, failureReasonFilesystem, fmt.Sprintf("linking stored blob: %v", err))
+		}
+	}
+	key := client.ObjectKeyFromObject(artifact)
+	if reused {
+		r.Scheduler.Forget(key)
+		artifact.Status.SourceURL = ""
+		artifact.Status.EffectiveURL = ""
+		artifact.Status.Provenance = nil
+		clearDrift(artifact)
+		artifact.Status.ResolvedDigest = resolvedDigest(artifact, want)
+		artifact.Status.DecompressedDigest = ""
+		recordVerified(ctx, filePath, want)
+		if err := r.setReady(ctx, artifact); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.removeStaleFiles(ctx, filePath)
+		log.Info("Artifact linked to stored blob, skipping download", "path", filePath, "digest", want.String())
+		return ctrl.Result{RequeueAfter: r.nextCheck(artifact)}, nil
+	}
+
+	if ok, position := r.Scheduler.TryAcquire(key, artifact.Spec.Priority); !ok {
+		return r.setQueued(ctx, artifact, position)
+	}
+	defer r.Scheduler.Release(key)
+
 	// Set phase to Downloading
 	artifact.Status.Phase = isobootgithubiov1alpha1.BootArtifactPhaseDownloading
 	artifact.Status.Message = "Downloading"
-	if err := r.Status().Update(ctx, artifact); err != nil {
+	if err := r.updateStatus(ctx, artifact); err != nil {
 		return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
 	}
 
@@ -136,89 +313,276 @@ func (r *BootArtifactReconciler) download(ctx context.Context, artifact *isoboot
 		return ctrl.Result{}, fmt.Errorf("re-fetching artifact: %w", err)
 	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
-		return r.setFailure(ctx, artifact, fmt.Sprintf("creating directory: %v", err))
+	rw, err := r.urlRewriter(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	urls := sourceURLs(artifact, rw, filePath+".tmp.meta")
+	failures := make([]string, 0, len(urls))
+	reason := failureReasonHashMismatch
+	for _, url := range urls {
+		effectiveURL := rw.rewrite(url)
+		start := time.Now()
+		served, prov, err := r.fetch(ctx, artifact, effectiveURL, filePath, want)
+		downloadDuration.WithLabelValues(downloadResult(err)).Observe(time.Since(start).Seconds())
+		if err == nil {
+			r.recordProvenance(artifact, url, prov)
+			clearDrift(artifact)
+			artifact.Status.SourceURL = url
+			artifact.Status.EffectiveURL = ""
+			if effectiveURL != url {
+				artifact.Status.EffectiveURL = effectiveURL
+			}
+			artifact.Status.ResolvedDigest = resolvedDigest(artifact, want)
+			artifact.Status.DecompressedDigest = ""
+			if artifact.Spec.Decompress != "" {
+				artifact.Status.DecompressedDigest = served.String()
+			}
+			recordVerified(ctx, filePath, served)
+			if err := r.setReady(ctx, artifact); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.removeStaleFiles(ctx, filePath)
+			log.Info("Artifact downloaded and verified", "path", filePath, "url", url, "effectiveURL", effectiveURL)
+			return ctrl.Result{RequeueAfter: r.nextCheck(artifact)}, nil
+		}
+		log.Info("Download from source failed", "url", url, "effectiveURL", effectiveURL, "error", err.Error())
+		if effectiveURL != url {
+			err = fmt.Errorf("via %s: %w", effectiveURL, err)
+		}
+		if spaceErr, ok := errors.AsType[*insufficientSpaceError](err); ok {
+			// No source will fit any better.
+			return r.setDiskPressure(ctx, artifact, spaceErr)
+		}
+		if !errors.Is(err, errHashMismatch) {
+			reason = failureReasonDownload
+		}
+		if len(urls) > 1 {
+			// Name each source so the status shows which mirror failed how.
+			failures = append(failures, fmt.Sprintf("%s: %v", url, err))
+		} else {
+			failures = append(failures, err.Error())
+		}
+	}
+	return r.setFailure(ctx, artifact, reason, strings.Join(failures, "; "))
+}
+
+// downloadResult is the result label of the download duration metric.
+func downloadResult(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// hasParentSegment reports whether rawURL has a ".." path segment before
+// any query or fragment, as the CRDs' path traversal rules do.
+func hasParentSegment(rawURL string) bool {
+	p, _, _ := strings.Cut(rawURL, "?")
+	p, _, _ = strings.Cut(p, "#")
+	return slices.Contains(strings.Split(p, "/"), "..")
+}
+
+// fetch downloads url into filePath via a .tmp file, resuming a compatible
+// partial download when possible, and verifies the digest before committing
+// the file to the blob store. It returns the digest of the committed file,
+// which differs from want for spec.decompress, and the provenance of the
+// download. The returned error is suitable for the status message.
+func (r *BootArtifactReconciler) fetch(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, url, filePath string, want digest) (digest, *isobootgithubiov1alpha1.BootArtifactProvenance, error) {
+	log := logf.FromContext(ctx)
+
+	if err := checkInsecureSource(artifact, url); err != nil {
+		return digest{}, nil, err
+	}
+	if hasParentSegment(url) {
+		// Only possible after an ArtifactMirrorPolicy rewrite.
+		return digest{}, nil, errors.New("url must not contain path traversal")
+	}
+	httpClient, err := r.sourceClient(ctx, artifact)
+	if err != nil {
+		return digest{}, nil, err
+	}
+
+	// An oci:// source is downloaded from its layer's blob URL, and a
+	// file:// source through a file transport rooted at FileSourceRoot; url
+	// still identifies the source for the partial download metadata.
+	reqURL, header := url, http.Header{}
+	switch {
+	case strings.HasPrefix(url, ociScheme):
+		layer, err := r.resolveOCILayer(ctx, artifact, url)
+		if err != nil {
+			return digest{}, nil, err
+		}
+		if layer.digest.algorithm == want.algorithm && !strings.EqualFold(layer.digest.hex, want.hex) {
+			return digest{}, nil, fmt.Errorf("layer digest %s does not match expected %s", layer.digest, want)
+		}
+		reqURL, header = layer.url, layer.header
+	case strings.HasPrefix(url, fileScheme):
+		root, fileClient, fileURL, err := r.openFileSource(url)
+		if err != nil {
+			return digest{}, nil, err
+		}
+		defer func() { _ = root.Close() }()
+		httpClient, reqURL = fileClient, fileURL
 	}
 
 	tmpPath := filePath + ".tmp"
+	metaPath := tmpPath + ".meta"
+	// keepPartial is set once the server has supplied a validator, so an
+	// interrupted transfer can be resumed on the next reconcile.
+	keepPartial := false
 	defer func() {
-		if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
-			log.Error(err, "Failed to clean up temp file", "path", tmpPath)
+		if keepPartial {
+			return
+		}
+		for _, p := range []string{tmpPath, metaPath} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				log.Error(err, "Failed to clean up temp file", "path", p)
+			}
 		}
 	}()
 
-	log.Info("Downloading artifact", "url", artifact.Spec.URL)
+	partial, offset := loadPartial(tmpPath, metaPath, url)
+
+	log.Info("Downloading artifact", "url", url, "offset", offset)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifact.Spec.URL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
-		return r.setFailure(ctx, artifact, fmt.Sprintf("creating request: %v", err))
+		return digest{}, nil, fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", partial.ifRange())
 	}
 
-	resp, err := r.HTTPClient.Do(req)
+	startTime := metav1.Now()
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return r.setFailure(ctx, artifact, fmt.Sprintf("download failed: %v", err))
+		keepPartial = offset > 0
+		return digest{}, nil, fmt.Errorf("download failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	if resp.ContentLength < 0 {
+		// The file transport sends the header but leaves the field unset.
+		if n, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+			resp.ContentLength = n
+		}
+	}
 
-	if resp.StatusCode != http.StatusOK {
+	// Refuse a download that cannot fit before any of it is read, rather
+	// than failing midway with ENOSPC. For a resumed download
+	// Content-Length only covers the remaining bytes.
+	if (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent) && resp.ContentLength > 0 {
+		if err := r.ensureSpace(ctx, artifact, resp.ContentLength); err != nil {
+			keepPartial = offset > 0
+			return digest{}, nil, err
+		}
+	}
+
+	var tmpFile *os.File
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+			return digest{}, nil, fmt.Errorf("unexpected Content-Range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+		}
+		if tmpFile, err = os.OpenFile(tmpPath, os.O_RDWR, 0o644); err != nil {
+			return digest{}, nil, fmt.Errorf("opening temp file: %w", err)
+		}
+	case resp.StatusCode == http.StatusOK:
+		// Full body: either a fresh download, or the server ignored Range or
+		// the If-Range validator no longer matched.
+		offset = 0
+		partial = partialDownload{
+			URL:          url,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+		if tmpFile, err = os.Create(tmpPath); err != nil {
+			return digest{}, nil, fmt.Errorf("creating temp file: %w", err)
+		}
+		if partial.ifRange() != "" {
+			if err := partial.save(metaPath); err != nil {
+				_ = tmpFile.Close()
+				return digest{}, nil, fmt.Errorf("writing download metadata: %w", err)
+			}
+		} else {
+			_ = os.Remove(metaPath)
+		}
+	default:
 		// Drain body (up to 1 MB) so the connection can be reused for keep-alive.
 		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
-		return r.setFailure(ctx, artifact, fmt.Sprintf("download failed: HTTP %d", resp.StatusCode))
+		// A 416 means the partial no longer lines up with the remote file;
+		// drop it so the next attempt starts over.
+		keepPartial = offset > 0 && resp.StatusCode != http.StatusRequestedRangeNotSatisfiable
+		return digest{}, nil, fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
 	}
 
-	// Create temp file and hash while downloading
-	tmpFile, err := os.Create(tmpPath)
-	if err != nil {
-		return r.setFailure(ctx, artifact, fmt.Sprintf("creating temp file: %v", err))
+	h := want.newHash()
+
+	// Hash the bytes already on disk so the digest covers the whole file,
+	// leaving the file offset at the end for appending.
+	if offset > 0 {
+		if _, err := io.CopyN(h, tmpFile, offset); err != nil {
+			_ = tmpFile.Close()
+			return digest{}, nil, fmt.Errorf("reading partial file: %w", err)
+		}
 	}
-	useSHA256 := artifact.Spec.SHA256 != nil
-	var h hash.Hash
-	if useSHA256 {
-		h = sha256.New()
-	} else {
-		h = sha512.New()
+
+	var total int64
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
 	}
+	progress := r.newProgressReporter(ctx, artifact, offset, total)
 
-	written, err := io.Copy(tmpFile, io.TeeReader(resp.Body, h))
+	body := r.Scheduler.Reader(ctx, resp.Body)
+	written, err := io.Copy(io.MultiWriter(tmpFile, progress), io.TeeReader(body, h))
+	downloadedBytes.Add(float64(written))
 	if err != nil {
 		_ = tmpFile.Close()
-		return r.setFailure(ctx, artifact, fmt.Sprintf("writing file: %v", err))
+		keepPartial = partial.ifRange() != ""
+		return digest{}, nil, fmt.Errorf("writing file: %w", err)
 	}
 	if resp.ContentLength >= 0 && written != resp.ContentLength {
 		_ = tmpFile.Close()
-		return r.setFailure(ctx, artifact, fmt.Sprintf("Content-Length mismatch: expected %d bytes, got %d", resp.ContentLength, written))
+		keepPartial = partial.ifRange() != ""
+		return digest{}, nil, fmt.Errorf("size mismatch: Content-Length %d bytes, got %d", resp.ContentLength, written)
 	}
 	if err := tmpFile.Sync(); err != nil {
 		_ = tmpFile.Close()
-		return r.setFailure(ctx, artifact, fmt.Sprintf("syncing temp file: %v", err))
+		return digest{}, nil, fmt.Errorf("syncing temp file: %w", err)
 	}
 	if err := tmpFile.Close(); err != nil {
-		return r.setFailure(ctx, artifact, fmt.Sprintf("closing temp file: %v", err))
+		return digest{}, nil, fmt.Errorf("closing temp file: %w", err)
 	}
 
 	computedHash := hex.EncodeToString(h.Sum(nil))
-	expectedHash := expectedHash(artifact)
+	expectedHash := want.hex
 
 	if !strings.EqualFold(computedHash, expectedHash) {
-		log.Info("Hash mismatch after download", "expected", expectedHash, "got", computedHash)
-		return r.setFailure(ctx, artifact, fmt.Sprintf("hash mismatch: expected %s got %s", expectedHash, computedHash))
+		log.Info("Hash mismatch after download", "url", url, "expected", expectedHash, "got", computedHash)
+		hashMismatches.WithLabelValues("download").Inc()
+		return digest{}, nil, fmt.Errorf("%w: expected %s got %s", errHashMismatch, expectedHash, computedHash)
 	}
 
-	// Atomic rename + directory fsync for crash durability
-	if err := os.Rename(tmpPath, filePath); err != nil {
-		return r.setFailure(ctx, artifact, fmt.Sprintf("renaming file: %v", err))
-	}
-	if err := syncDir(filepath.Dir(filePath)); err != nil {
-		return r.setFailure(ctx, artifact, fmt.Sprintf("syncing directory: %v", err))
+	served, err := r.commitDownload(artifact, tmpPath, filePath, want)
+	if err != nil {
+		return digest{}, nil, err
 	}
-
-	if err := r.setReady(ctx, artifact); err != nil {
-		return ctrl.Result{}, err
+	completionTime := metav1.Now()
+	prov := &isobootgithubiov1alpha1.BootArtifactProvenance{
+		FinalURL:       finalURL(url, resp),
+		ETag:           cmp.Or(resp.Header.Get("ETag"), partial.ETag),
+		LastModified:   cmp.Or(resp.Header.Get("Last-Modified"), partial.LastModified),
+		ContentLength:  offset + written,
+		StartTime:      &startTime,
+		CompletionTime: &completionTime,
+		Digest:         digest{algorithm: want.algorithm, hex: computedHash}.String(),
 	}
-
-	log.Info("Artifact downloaded and verified", "path", filePath)
-	return ctrl.Result{}, nil
+	return served, prov, nil
 }
 
 func (r *BootArtifactReconciler) setReady(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact) error {
@@ -228,21 +592,96 @@ func (r *BootArtifactReconciler) setReady(ctx context.Context, artifact *isoboot
 	artifact.Status.FailureCount = 0
 	artifact.Status.LastFailureTime = nil
 	artifact.Status.LastChecked = &now
-	if err := r.Status().Update(ctx, artifact); err != nil {
+	artifact.Status.ObservedGeneration = artifact.Generation
+	artifact.Status.Progress = nil
+	meta.RemoveStatusCondition(&artifact.Status.Conditions, isobootgithubiov1alpha1.BootArtifactConditionDiskPressure)
+	if err := r.updateStatus(ctx, artifact); err != nil {
 		return fmt.Errorf("updating status: %w", err)
 	}
 	return nil
 }
 
-func (r *BootArtifactReconciler) setFailure(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, message string) (ctrl.Result, error) {
+// updateStatus writes the status with Ready, Progressing and Degraded
+// derived from the phase.
+func (r *BootArtifactReconciler) updateStatus(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact) error {
+	setArtifactConditions(artifact, "")
+	return r.Status().Update(ctx, artifact)
+}
+
+// setQueued records that the artifact is waiting for a download slot. The
+// scheduler wakes it when a slot frees up; the requeue is a fallback.
+func (r *BootArtifactReconciler) setQueued(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, position int) (ctrl.Result, error) {
+	message := fmt.Sprintf("Waiting for a download slot (position %d)", position)
+	if artifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseQueued || artifact.Status.Message != message {
+		artifact.Status.Phase = isobootgithubiov1alpha1.BootArtifactPhaseQueued
+		artifact.Status.Message = message
+		if err := r.updateStatus(ctx, artifact); err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+		}
+	}
+	return ctrl.Result{RequeueAfter: queuedRequeueInterval}, nil
+}
+
+// finalize removes the artifact's directory and then its finalizer.
+func (r *BootArtifactReconciler) finalize(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact) error {
+	if !controllerutil.ContainsFinalizer(artifact, bootArtifactFinalizer) {
+		return nil
+	}
+	dir := filepath.Dir(r.filePath(artifact))
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing artifact directory: %w", err)
+	}
+	logf.FromContext(ctx).Info("Removed artifact directory", "path", dir)
+	r.pruneBlobs(ctx)
+	controllerutil.RemoveFinalizer(artifact, bootArtifactFinalizer)
+	if err := r.Update(ctx, artifact); err != nil {
+		return fmt.Errorf("removing finalizer: %w", err)
+	}
+	return nil
+}
+
+// removeStaleFiles deletes everything in the artifact directory except the
+// current file and its in-progress download, e.g. the file left behind under
+// its old name after spec.url changed, then prunes blobs nothing links to any
+// more. Failures are logged and retried on the next reconcile.
+func (r *BootArtifactReconciler) removeStaleFiles(ctx context.Context, filePath string) {
 	log := logf.FromContext(ctx)
-	log.Info("Artifact failure", "message", message)
+	dir, name := filepath.Split(filePath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Error(err, "Failed to list artifact directory", "path", dir)
+		return
+	}
+	keep := []string{name, name + ".tmp", name + ".tmp.meta", name + verifiedSuffix}
+	for _, e := range entries {
+		if slices.Contains(keep, e.Name()) {
+			continue
+		}
+		stale := filepath.Join(dir, e.Name())
+		if err := os.RemoveAll(stale); err != nil {
+			log.Error(err, "Failed to remove stale file", "path", stale)
+			continue
+		}
+		log.Info("Removed stale file", "path", stale)
+	}
+	r.pruneBlobs(ctx)
+}
+
+// setFailure moves the artifact to Error and counts the failure under reason
+// in the isoboot_artifact_failures_total metric. reason is also the reason
+// of the Degraded condition.
+func (r *BootArtifactReconciler) setFailure(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, reason, message string) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	log.Info("Artifact failure", "reason", reason, "message", message)
+	artifactFailures.WithLabelValues(reason).Inc()
 
 	now := metav1.Now()
 	artifact.Status.Phase = isobootgithubiov1alpha1.BootArtifactPhaseError
 	artifact.Status.Message = message
 	artifact.Status.FailureCount++
 	artifact.Status.LastFailureTime = &now
+	artifact.Status.Progress = nil
+	setArtifactConditions(artifact, reason)
 	if err := r.Status().Update(ctx, artifact); err != nil {
 		return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
 	}
@@ -252,19 +691,186 @@ func (r *BootArtifactReconciler) setFailure(ctx context.Context, artifact *isobo
 	return ctrl.Result{RequeueAfter: backoff}, nil
 }
 
+// verificationDue reports whether periodic verification is enabled and the
+// file was last verified (status.lastChecked) more than VerifyInterval ago.
+func (r *BootArtifactReconciler) verificationDue(artifact *isobootgithubiov1alpha1.BootArtifact) bool {
+	return r.VerifyInterval > 0 &&
+		(artifact.Status.LastChecked == nil || time.Since(artifact.Status.LastChecked.Time) >= r.VerifyInterval)
+}
+
+// nextVerification returns the requeue delay until the next periodic
+// verification, or zero when it is disabled.
+func (r *BootArtifactReconciler) nextVerification(artifact *isobootgithubiov1alpha1.BootArtifact) time.Duration {
+	if r.VerifyInterval <= 0 {
+		return 0
+	}
+	if artifact.Status.LastChecked == nil {
+		return r.VerifyInterval
+	}
+	return max(time.Until(artifact.Status.LastChecked.Add(r.VerifyInterval)), time.Second)
+}
+
 func (r *BootArtifactReconciler) filePath(artifact *isobootgithubiov1alpha1.BootArtifact) string {
-	filename := urlutil.FilenameFromURL(artifact.Spec.URL)
+	filename := urlutil.ArtifactFilename(artifact.Spec.URL, string(artifact.Spec.Decompress))
 	return filepath.Join(r.DataDir, "artifacts", artifact.Name, filename)
 }
 
-func expectedHash(artifact *isobootgithubiov1alpha1.BootArtifact) string {
-	if artifact.Spec.SHA256 != nil {
-		return *artifact.Spec.SHA256
+// expectedDigest returns the digest the artifact must match: spec.sha256 or
+// spec.sha512 when set, otherwise the digest resolved from spec.checksums or
+// from the layer of an oci:// spec.url. The digest recorded in
+// status.resolvedDigest is reused unless refresh is set.
+func (r *BootArtifactReconciler) expectedDigest(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, refresh bool) (digest, error) {
+	switch {
+	case artifact.Spec.SHA256 != nil:
+		return digest{algorithm: "sha256", hex: *artifact.Spec.SHA256}, nil
+	case artifact.Spec.SHA512 != nil:
+		return digest{algorithm: "sha512", hex: *artifact.Spec.SHA512}, nil
+	case artifact.Spec.Checksums == nil && !strings.HasPrefix(artifact.Spec.URL, ociScheme):
+		return digest{}, fmt.Errorf("no digest configured")
+	}
+	if !refresh {
+		if d, ok := parseDigest(artifact.Status.ResolvedDigest); ok {
+			return d, nil
+		}
+	}
+	if artifact.Spec.Checksums != nil {
+		return r.resolveChecksums(ctx, artifact)
+	}
+	rw, err := r.urlRewriter(ctx)
+	if err != nil {
+		return digest{}, err
+	}
+	layer, err := r.resolveOCILayer(ctx, artifact, rw.rewrite(artifact.Spec.URL))
+	if err != nil {
+		return digest{}, err
+	}
+	return layer.digest, nil
+}
+
+// adoptImportedDigests records the digests of an imported artifact's
+// BootArtifactAnnotationImportedDigests in status, then removes the
+// annotation. They are only trusted before the artifact was first
+// reconciled; later, the annotation is just removed.
+func (r *BootArtifactReconciler) adoptImportedDigests(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact) error {
+	value, ok := artifact.Annotations[isobootgithubiov1alpha1.BootArtifactAnnotationImportedDigests]
+	if !ok {
+		return nil
+	}
+	var imported struct {
+		ResolvedDigest     string `json:"resolvedDigest"`
+		DecompressedDigest string `json:"decompressedDigest"`
+	}
+	if artifact.Status.ObservedGeneration == 0 && json.Unmarshal([]byte(value), &imported) == nil {
+		artifact.Status.ResolvedDigest = imported.ResolvedDigest
+		artifact.Status.DecompressedDigest = imported.DecompressedDigest
+		artifact.Status.FileSpecHash = fileSpecHash(artifact)
+		if err := r.Status().Update(ctx, artifact); err != nil {
+			return fmt.Errorf("recording imported digests: %w", err)
+		}
+	}
+	delete(artifact.Annotations, isobootgithubiov1alpha1.BootArtifactAnnotationImportedDigests)
+	if err := r.Update(ctx, artifact); err != nil {
+		return fmt.Errorf("removing imported digests annotation: %w", err)
+	}
+	return nil
+}
+
+// fileSpecHash is the status.fileSpecHash value for the artifact's spec.
+func fileSpecHash(artifact *isobootgithubiov1alpha1.BootArtifact) string {
+	data, _ := json.Marshal(struct {
+		URL        string                                          `json:"url"`
+		SHA256     *string                                         `json:"sha256,omitempty"`
+		SHA512     *string                                         `json:"sha512,omitempty"`
+		Checksums  *isobootgithubiov1alpha1.BootArtifactChecksums  `json:"checksums,omitempty"`
+		Decompress isobootgithubiov1alpha1.BootArtifactCompression `json:"decompress,omitempty"`
+	}{artifact.Spec.URL, artifact.Spec.SHA256, artifact.Spec.SHA512, artifact.Spec.Checksums, artifact.Spec.Decompress})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// resolvedDigest is the status.resolvedDigest value for want: set only when
+// the digest was resolved rather than given in spec.sha256 or spec.sha512.
+func resolvedDigest(artifact *isobootgithubiov1alpha1.BootArtifact, want digest) string {
+	if artifact.Spec.SHA256 != nil || artifact.Spec.SHA512 != nil {
+		return ""
 	}
-	if artifact.Spec.SHA512 != nil {
-		return *artifact.Spec.SHA512
+	return want.String()
+}
+
+// sourceURLs returns spec.url followed by spec.mirrors. If a resumable partial
+// download recorded at metaPath came from one of them, as rewritten by rw,
+// that source is moved to the front so the partial is not discarded by trying
+// another source first.
+func sourceURLs(artifact *isobootgithubiov1alpha1.BootArtifact, rw urlRewriter, metaPath string) []string {
+	urls := append([]string{artifact.Spec.URL}, artifact.Spec.Mirrors...)
+	var p partialDownload
+	if data, err := os.ReadFile(metaPath); err == nil && json.Unmarshal(data, &p) == nil {
+		if i := slices.IndexFunc(urls, func(u string) bool { return rw.rewrite(u) == p.URL }); i > 0 {
+			urls = append([]string{urls[i]}, slices.Delete(urls, i, i+1)...)
+		}
 	}
-	return ""
+	return urls
+}
+
+// partialDownload is persisted next to an in-progress .tmp file so a later
+// reconcile (or a restarted controller) can resume it with a Range request.
+type partialDownload struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// ifRange returns the If-Range validator for the partial: a strong ETag if the
+// server sent one, otherwise Last-Modified. Weak ETags are not allowed in
+// If-Range, so they yield "" unless Last-Modified is available.
+func (p partialDownload) ifRange() string {
+	if p.ETag != "" && !strings.HasPrefix(p.ETag, "W/") {
+		return p.ETag
+	}
+	return p.LastModified
+}
+
+func (p partialDownload) save(path string) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// loadPartial returns the recorded partial download for url and the number of
+// bytes already on disk. It discards the partial (returning offset 0) when the
+// metadata is missing, unreadable, lacks a validator, or was recorded for a
+// different URL.
+func loadPartial(tmpPath, metaPath, url string) (partialDownload, int64) {
+	var p partialDownload
+	info, statErr := os.Stat(tmpPath)
+	data, readErr := os.ReadFile(metaPath)
+	if statErr == nil && readErr == nil && json.Unmarshal(data, &p) == nil &&
+		p.URL == url && p.ifRange() != "" && info.Size() > 0 {
+		return p, info.Size()
+	}
+	_ = os.Remove(tmpPath)
+	_ = os.Remove(metaPath)
+	return partialDownload{}, 0
+}
+
+// contentRangeStart parses the first byte position from a Content-Range
+// header of the form "bytes start-end/total".
+func contentRangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, false
+	}
+	return start, true
 }
 
 func syncDir(path string) error {
@@ -276,20 +882,16 @@ func syncDir(path string) error {
 	return d.Sync()
 }
 
-func hashFile(path string, useSHA256 bool) (string, error) {
+func hashFile(path string, want digest) (string, error) {
+	defer func(start time.Time) { hashDuration.Observe(time.Since(start).Seconds()) }(time.Now())
+
 	f, err := os.Open(path)
 	if err != nil {
 		return "", err
 	}
 	defer func() { _ = f.Close() }()
 
-	var h hash.Hash
-	if useSHA256 {
-		h = sha256.New()
-	} else {
-		h = sha512.New()
-	}
-
+	h := want.newHash()
 	if _, err := io.Copy(h, f); err != nil {
 		return "", err
 	}
@@ -301,8 +903,24 @@ func (r *BootArtifactReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if r.HTTPClient == nil {
 		r.HTTPClient = &http.Client{Timeout: 30 * time.Minute}
 	}
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&isobootgithubiov1alpha1.BootArtifact{}).
-		Named("bootartifact").
-		Complete(r)
+		Watches(&isobootgithubiov1alpha1.BootConfig{}, handler.EnqueueRequestsFromMapFunc(
+			r.findEvictedArtifactsForBootConfig,
+		)).
+		Watches(&isobootgithubiov1alpha1.ArtifactMirrorPolicy{}, handler.EnqueueRequestsFromMapFunc(
+			r.findFailedArtifactsForPolicy,
+		)).
+		Named("bootartifact")
+	if r.Scheduler != nil {
+		// Run one more worker than there are download slots, so verifying
+		// and queueing other artifacts is not blocked by running downloads.
+		workers := r.Scheduler.maxConcurrent + 1
+		if r.Scheduler.maxConcurrent <= 0 {
+			workers = unlimitedDownloadWorkers
+		}
+		b = b.WithOptions(controller.Options{MaxConcurrentReconciles: workers}).
+			WatchesRawSource(source.Channel(r.Scheduler.Wakeups(), &handler.EnqueueRequestForObject{}))
+	}
+	return b.Complete(r)
 }
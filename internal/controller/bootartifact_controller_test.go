@@ -17,6 +17,7 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"crypto/sha512"
@@ -26,10 +27,19 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -65,6 +75,12 @@ var _ = Describe("BootArtifact Controller", func() {
 	Context("Validation", func() {
 		ctx := context.Background()
 
+		validChecksums := isobootgithubiov1alpha1.BootArtifactChecksums{
+			URL:          "https://example.com/SHA256SUMS",
+			SignatureURL: "https://example.com/SHA256SUMS.gpg",
+			Keyring:      isobootgithubiov1alpha1.ConfigMapKeyReference{Name: "distro-keys", Key: "keys.asc"},
+		}
+
 		newArtifact := func(name string, spec isobootgithubiov1alpha1.BootArtifactSpec) *isobootgithubiov1alpha1.BootArtifact {
 			return &isobootgithubiov1alpha1.BootArtifact{
 				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
@@ -80,6 +96,15 @@ var _ = Describe("BootArtifact Controller", func() {
 			},
 			Entry("sha256 only", "valid-sha256", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/vmlinuz", SHA256: new(validSHA256)}),
 			Entry("sha512 only", "valid-sha512", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/vmlinuz", SHA512: new(validSHA512)}),
+			Entry("with mirrors", "valid-mirrors", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/vmlinuz", Mirrors: []string{"https://mirror.example.org/vmlinuz"}, SHA256: new(validSHA256)}),
+			Entry("signed checksums", "valid-checksums", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/vmlinuz", Checksums: &validChecksums}),
+			Entry("oci reference without digest", "valid-oci", isobootgithubiov1alpha1.BootArtifactSpec{URL: "oci://registry.example.com/debian/vmlinuz:13", ImagePullSecret: &isobootgithubiov1alpha1.SecretReference{Name: "pull"}}),
+			Entry("oci mirror", "valid-oci-mirror", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/vmlinuz", Mirrors: []string{"oci://registry.example.com/debian/vmlinuz:13"}, SHA256: new(validSHA256)}),
+			Entry("with priority", "valid-priority", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/vmlinuz", SHA256: new(validSHA256), Priority: -10}),
+			Entry("with decompress", "valid-decompress", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/vmlinuz.xz", SHA256: new(validSHA256), Decompress: isobootgithubiov1alpha1.BootArtifactCompressionXZ}),
+			Entry("insecure http url", "valid-http", isobootgithubiov1alpha1.BootArtifactSpec{URL: "http://mirror.lan/vmlinuz", SHA256: new(validSHA256), AllowInsecureSource: true}),
+			Entry("dots inside path segments", "valid-dots", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/..debian/vmlinuz..13", Mirrors: []string{"https://mirror.example.org/a..b/vmlinuz"}, SHA256: new(validSHA256)}),
+			Entry("insecure file mirror", "valid-file-mirror", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/vmlinuz", Mirrors: []string{"file:///srv/mirror/vmlinuz"}, SHA512: new(validSHA512), AllowInsecureSource: true}),
 		)
 
 		DescribeTable("should reject invalid specs",
@@ -97,6 +122,18 @@ var _ = Describe("BootArtifact Controller", func() {
 			Entry("empty url", "empty", isobootgithubiov1alpha1.BootArtifactSpec{URL: "", SHA256: new(validSHA256)}),
 			Entry("path traversal", "traversal", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/foo/../bar", SHA256: new(validSHA256)}),
 			Entry("path traversal at end", "traversal-end", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/..", SHA256: new(validSHA256)}),
+			Entry("path traversal before query", "traversal-query", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/foo/..?x=1", SHA256: new(validSHA256)}),
+			Entry("http mirror", "http-mirror", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/f", Mirrors: []string{"http://mirror.example.org/f"}, SHA256: new(validSHA256)}),
+			Entry("mirror path traversal", "traversal-mirror", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/f", Mirrors: []string{"https://mirror.example.org/../f"}, SHA256: new(validSHA256)}),
+			Entry("checksums and sha256 set", "checksums-sha256", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/f", Checksums: &validChecksums, SHA256: new(validSHA256)}),
+			Entry("http checksums url", "http-checksums", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/f", Checksums: &isobootgithubiov1alpha1.BootArtifactChecksums{URL: "http://example.com/SHA256SUMS", SignatureURL: validChecksums.SignatureURL, Keyring: validChecksums.Keyring}}),
+			Entry("file url", "file", isobootgithubiov1alpha1.BootArtifactSpec{URL: "file:///srv/mirror/f", SHA256: new(validSHA256)}),
+			Entry("insecure source with checksums", "insecure-checksums", isobootgithubiov1alpha1.BootArtifactSpec{URL: "http://mirror.lan/f", Checksums: &validChecksums, AllowInsecureSource: true}),
+			Entry("insecure file path traversal", "insecure-traversal", isobootgithubiov1alpha1.BootArtifactSpec{URL: "file:///srv/mirror/../../etc/shadow", SHA256: new(validSHA256), AllowInsecureSource: true}),
+			Entry("ftp url", "ftp", isobootgithubiov1alpha1.BootArtifactSpec{URL: "ftp://example.com/f", SHA256: new(validSHA256)}),
+			Entry("priority out of range", "priority-range", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/f", SHA256: new(validSHA256), Priority: 1001}),
+			Entry("unknown decompress format", "decompress-bzip2", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/f.bz2", SHA256: new(validSHA256), Decompress: "bzip2"}),
+			Entry("checksums without keyring name", "checksums-nokeyring", isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/f", Checksums: &isobootgithubiov1alpha1.BootArtifactChecksums{URL: validChecksums.URL, SignatureURL: validChecksums.SignatureURL, Keyring: isobootgithubiov1alpha1.ConfigMapKeyReference{Key: "keys.asc"}}}),
 		)
 	})
 
@@ -141,9 +178,18 @@ var _ = Describe("BootArtifact Controller", func() {
 			var a isobootgithubiov1alpha1.BootArtifact
 			if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a); err == nil {
 				_ = k8sClient.Delete(ctx, &a)
+				// Run the finalizer so the object is actually removed.
+				_, _ = doReconcile(name)
 			}
 		}
 
+		updateSpec := func(name string, mutate func(*isobootgithubiov1alpha1.BootArtifactSpec)) {
+			var a isobootgithubiov1alpha1.BootArtifact
+			ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)).To(Succeed())
+			mutate(&a.Spec)
+			ExpectWithOffset(1, k8sClient.Update(ctx, &a)).To(Succeed())
+		}
+
 		It("should return without error for deleted resource", func() {
 			result, err := doReconcile("nonexistent")
 			Expect(err).NotTo(HaveOccurred())
@@ -167,6 +213,9 @@ var _ = Describe("BootArtifact Controller", func() {
 			status := getStatus(name)
 			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
 			Expect(status.FailureCount).To(Equal(int32(0)))
+			Expect(meta.IsStatusConditionTrue(status.Conditions, isobootgithubiov1alpha1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(status.Conditions, isobootgithubiov1alpha1.ConditionProgressing)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(status.Conditions, isobootgithubiov1alpha1.ConditionDegraded)).To(BeTrue())
 
 			data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
 			Expect(err).NotTo(HaveOccurred())
@@ -205,6 +254,10 @@ var _ = Describe("BootArtifact Controller", func() {
 			createArtifact(name, serverURL+"/vmlinuz", wrongSHA256)
 			defer deleteArtifact(name)
 
+			mismatches := testutil.ToFloat64(hashMismatches.WithLabelValues("download"))
+			failures := testutil.ToFloat64(artifactFailures.WithLabelValues(failureReasonHashMismatch))
+			received := testutil.ToFloat64(downloadedBytes)
+
 			result, err := doReconcile(name)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.RequeueAfter).NotTo(BeZero())
@@ -213,6 +266,14 @@ var _ = Describe("BootArtifact Controller", func() {
 			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
 			Expect(status.FailureCount).To(Equal(int32(1)))
 			Expect(status.Message).To(ContainSubstring("hash mismatch"))
+			degraded := meta.FindStatusCondition(status.Conditions, isobootgithubiov1alpha1.ConditionDegraded)
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal(failureReasonHashMismatch))
+			Expect(meta.IsStatusConditionFalse(status.Conditions, isobootgithubiov1alpha1.ConditionReady)).To(BeTrue())
+			Expect(testutil.ToFloat64(hashMismatches.WithLabelValues("download"))).To(Equal(mismatches + 1))
+			Expect(testutil.ToFloat64(artifactFailures.WithLabelValues(failureReasonHashMismatch))).To(Equal(failures + 1))
+			Expect(testutil.ToFloat64(downloadedBytes)).To(BeNumerically(">=", received+float64(len("content"))))
 		})
 
 		It("should set Error on HTTP 404", func() {
@@ -276,6 +337,145 @@ var _ = Describe("BootArtifact Controller", func() {
 			Expect(data).To(Equal(content))
 		})
 
+		It("should re-verify periodically and re-download a corrupted file", func() {
+			content := []byte("scrubbed kernel")
+			var requests atomic.Int32
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				_, _ = w.Write(content)
+			})
+			defer cleanup()
+			recorder := events.NewFakeRecorder(10)
+			reconciler.HTTPClient = httpClient
+			reconciler.Recorder = recorder
+			reconciler.VerifyInterval = time.Hour
+
+			name := "scrub"
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(content))
+			defer deleteArtifact(name)
+
+			result, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal Downloaded")))
+
+			// Not due yet: the next check stays scheduled from lastChecked.
+			result, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			// Rot the file in place and make the check due.
+			filePath := filepath.Join(dataDir, "artifacts", name, "vmlinuz")
+			Expect(os.WriteFile(filePath, []byte("bit rot"), 0o644)).To(Succeed())
+			var a isobootgithubiov1alpha1.BootArtifact
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)).To(Succeed())
+			a.Status.LastChecked = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+			Expect(k8sClient.Status().Update(ctx, &a)).To(Succeed())
+
+			result, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning Corrupted")))
+			Expect(requests.Load()).To(Equal(int32(2)))
+
+			status := getStatus(name)
+			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			Expect(status.LastChecked.Time).To(BeTemporally("~", time.Now(), time.Minute))
+			data, err := os.ReadFile(filePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(content))
+		})
+
+		It("should record the provenance of a download and report upstream changes", func() {
+			content := []byte("provenance kernel")
+			var etag atomic.Pointer[string]
+			etag.Store(new(`"v1"`))
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/vmlinuz" {
+					http.Redirect(w, r, "/pool/vmlinuz?token=secret", http.StatusFound)
+					return
+				}
+				w.Header().Set("ETag", *etag.Load())
+				w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+				_, _ = w.Write(content)
+			})
+			defer cleanup()
+			recorder := events.NewFakeRecorder(10)
+			reconciler.HTTPClient = httpClient
+			reconciler.Recorder = recorder
+
+			name := "provenance"
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(content))
+			defer deleteArtifact(name)
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			prov := getStatus(name).Provenance
+			Expect(prov).NotTo(BeNil())
+			Expect(prov.FinalURL).To(Equal(serverURL + "/pool/vmlinuz"))
+			Expect(prov.ETag).To(Equal(`"v1"`))
+			Expect(prov.LastModified).To(Equal("Mon, 02 Jan 2006 15:04:05 GMT"))
+			Expect(prov.ContentLength).To(Equal(int64(len(content))))
+			Expect(prov.Digest).To(Equal("sha256:" + sha256Hex(content)))
+			Expect(prov.StartTime).NotTo(BeNil())
+			Expect(prov.CompletionTime.Time).NotTo(BeTemporally("<", prov.StartTime.Time))
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal Downloaded")))
+
+			// The same bytes republished under a new ETag, and fetched again
+			// since neither the file nor its blob is left.
+			etag.Store(new(`"v2"`))
+			Expect(os.Remove(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))).To(Succeed())
+			Expect(os.RemoveAll(filepath.Join(dataDir, "blobs"))).To(Succeed())
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Provenance.ETag).To(Equal(`"v2"`))
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal Downloaded")))
+			Expect(recorder.Events).To(Receive(And(HavePrefix("Normal SourceChanged"), ContainSubstring(`ETag "\"v1\"" -> "\"v2\""`))))
+		})
+
+		It("should skip rehashing an unchanged file with a verification record", func() {
+			content := []byte("cached kernel")
+			var requests atomic.Int32
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				_, _ = w.Write(content)
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "verify-cache"
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(content))
+			defer deleteArtifact(name)
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			filePath := filepath.Join(dataDir, "artifacts", name, "vmlinuz")
+			Expect(filePath + verifiedSuffix).To(BeAnExistingFile())
+
+			// Rewrite the file with same-length garbage but keep its mtime, so
+			// only a rehash could notice the change.
+			info, err := os.Stat(filePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filePath, []byte("garbage bytes"), 0o644)).To(Succeed())
+			Expect(os.Chtimes(filePath, info.ModTime(), info.ModTime())).To(Succeed())
+
+			// A fresh reconciler, as after a restart, trusts the record.
+			reconciler = &BootArtifactReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir, HTTPClient: httpClient}
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			Expect(requests.Load()).To(Equal(int32(1)))
+
+			// A changed mtime invalidates the record and the file is rehashed.
+			Expect(os.Chtimes(filePath, time.Now(), time.Now())).To(Succeed())
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests.Load()).To(Equal(int32(2)))
+			data, err := os.ReadFile(filePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(content))
+		})
+
 		It("should succeed when Content-Length header is missing (chunked)", func() {
 			content := []byte("streamed content")
 			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -322,6 +522,274 @@ var _ = Describe("BootArtifact Controller", func() {
 			Expect(status.FailureCount).To(Equal(int32(1)))
 		})
 
+		It("should resume a partial download with a Range request", func() {
+			content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+			var gotRange, gotIfRange string
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				gotRange, gotIfRange = r.Header.Get("Range"), r.Header.Get("If-Range")
+				w.Header().Set("ETag", `"v1"`)
+				http.ServeContent(w, r, "vmlinuz", time.Time{}, bytes.NewReader(content))
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "dl-resume"
+			tmpPath := filepath.Join(dataDir, "artifacts", name, "vmlinuz.tmp")
+			Expect(os.MkdirAll(filepath.Dir(tmpPath), 0o755)).To(Succeed())
+			Expect(os.WriteFile(tmpPath, content[:10], 0o644)).To(Succeed())
+			Expect(partialDownload{URL: serverURL + "/vmlinuz", ETag: `"v1"`}.save(tmpPath + ".meta")).To(Succeed())
+
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(content))
+			defer deleteArtifact(name)
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			Expect(gotRange).To(Equal("bytes=10-"))
+			Expect(gotIfRange).To(Equal(`"v1"`))
+
+			data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(content))
+			_, err = os.Stat(tmpPath + ".meta")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("should fall back to a full download when the server ignores Range", func() {
+			content := []byte("full body from a server without range support")
+			var gotRange string
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				gotRange = r.Header.Get("Range")
+				w.Header().Set("ETag", `"v1"`)
+				_, _ = w.Write(content)
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "dl-no-range"
+			tmpPath := filepath.Join(dataDir, "artifacts", name, "vmlinuz.tmp")
+			Expect(os.MkdirAll(filepath.Dir(tmpPath), 0o755)).To(Succeed())
+			Expect(os.WriteFile(tmpPath, []byte("stale partial"), 0o644)).To(Succeed())
+			Expect(partialDownload{URL: serverURL + "/vmlinuz", ETag: `"v1"`}.save(tmpPath + ".meta")).To(Succeed())
+
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(content))
+			defer deleteArtifact(name)
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gotRange).NotTo(BeEmpty())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+
+			data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(content))
+		})
+
+		It("should keep partial data across a failed transfer and resume it", func() {
+			content := []byte("the quick brown fox jumps over the lazy dog")
+			requests := 0
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("ETag", `"v1"`)
+				if requests == 1 {
+					// Declare the full length but cut the transfer short.
+					w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+					_, _ = w.Write(content[:20])
+					return
+				}
+				http.ServeContent(w, r, "vmlinuz", time.Time{}, bytes.NewReader(content))
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "dl-interrupted"
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(content))
+			defer deleteArtifact(name)
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+			partialData, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "vmlinuz.tmp"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partialData).To(Equal(content[:20]))
+
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(content))
+		})
+
+		It("should discard the partial file on hash mismatch", func() {
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				_, _ = w.Write([]byte("content"))
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "dl-partial-bad-hash"
+			createArtifact(name, serverURL+"/vmlinuz", wrongSHA256)
+			defer deleteArtifact(name)
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+			_, err = os.Stat(filepath.Join(dataDir, "artifacts", name, "vmlinuz.tmp"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("should fail over to the next mirror and record it", func() {
+			content := []byte("mirrored kernel")
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/good/vmlinuz":
+					_, _ = w.Write(content)
+				case "/corrupt/vmlinuz":
+					_, _ = w.Write([]byte("tampered"))
+				default:
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "dl-mirror"
+			resource := &isobootgithubiov1alpha1.BootArtifact{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec: isobootgithubiov1alpha1.BootArtifactSpec{
+					URL:     serverURL + "/down/vmlinuz",
+					Mirrors: []string{serverURL + "/corrupt/vmlinuz", serverURL + "/good/vmlinuz"},
+					SHA256:  new(sha256Hex(content)),
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			defer deleteArtifact(name)
+
+			result, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			status := getStatus(name)
+			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			Expect(status.SourceURL).To(Equal(serverURL + "/good/vmlinuz"))
+
+			data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(content))
+		})
+
+		It("should report every mirror when all of them fail", func() {
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) })
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "dl-mirror-all-fail"
+			resource := &isobootgithubiov1alpha1.BootArtifact{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec: isobootgithubiov1alpha1.BootArtifactSpec{
+					URL:     serverURL + "/a/vmlinuz",
+					Mirrors: []string{serverURL + "/b/vmlinuz"},
+					SHA256:  new(validSHA256),
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			defer deleteArtifact(name)
+
+			result, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).NotTo(BeZero())
+
+			status := getStatus(name)
+			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+			Expect(status.FailureCount).To(Equal(int32(1)))
+			Expect(status.Message).To(ContainSubstring(serverURL + "/a/vmlinuz: download failed: HTTP 404"))
+			Expect(status.Message).To(ContainSubstring(serverURL + "/b/vmlinuz: download failed: HTTP 404"))
+		})
+
+		It("should download a plain http source when allowInsecureSource is set", func() {
+			content := []byte("lan mirror kernel")
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(content) }))
+			defer server.Close()
+			reconciler.HTTPClient = server.Client()
+
+			name := "dl-insecure-http"
+			resource := &isobootgithubiov1alpha1.BootArtifact{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec: isobootgithubiov1alpha1.BootArtifactSpec{
+					URL:                 server.URL + "/vmlinuz",
+					SHA256:              new(sha256Hex(content)),
+					AllowInsecureSource: true,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			defer deleteArtifact(name)
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		})
+
+		It("should copy a file:// source from the file source root", func() {
+			sourceRoot, err := os.MkdirTemp("", "isoboot-sources-*")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(sourceRoot) }()
+			content := []byte("nfs kernel")
+			Expect(os.MkdirAll(filepath.Join(sourceRoot, "debian"), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(sourceRoot, "debian", "vmlinuz"), content, 0o644)).To(Succeed())
+			reconciler.FileSourceRoot = sourceRoot
+
+			name := "dl-file"
+			resource := &isobootgithubiov1alpha1.BootArtifact{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec: isobootgithubiov1alpha1.BootArtifactSpec{
+					URL:                 "file://" + filepath.Join(sourceRoot, "debian", "vmlinuz"),
+					SHA256:              new(sha256Hex(content)),
+					AllowInsecureSource: true,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			defer deleteArtifact(name)
+
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(content))
+		})
+
+		It("should refuse a file:// source outside the file source root", func() {
+			sourceRoot, err := os.MkdirTemp("", "isoboot-sources-*")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(sourceRoot) }()
+			outside := filepath.Join(filepath.Dir(sourceRoot), filepath.Base(sourceRoot)+"-secret")
+			Expect(os.WriteFile(outside, []byte("secret"), 0o644)).To(Succeed())
+			defer func() { _ = os.Remove(outside) }()
+			Expect(os.Symlink(outside, filepath.Join(sourceRoot, "link"))).To(Succeed())
+			reconciler.FileSourceRoot = sourceRoot
+
+			for name, path := range map[string]string{"dl-file-outside": outside, "dl-file-symlink": filepath.Join(sourceRoot, "link")} {
+				resource := &isobootgithubiov1alpha1.BootArtifact{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Spec: isobootgithubiov1alpha1.BootArtifactSpec{
+						URL:                 "file://" + path,
+						SHA256:              new(sha256Hex([]byte("secret"))),
+						AllowInsecureSource: true,
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+				defer deleteArtifact(name)
+
+				_, err := doReconcile(name)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+				_, err = os.Stat(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			}
+		})
+
 		It("should increment failureCount on repeated failures", func() {
 			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(500) })
 			defer cleanup()
@@ -337,5 +805,233 @@ var _ = Describe("BootArtifact Controller", func() {
 				Expect(getStatus(name).FailureCount).To(Equal(i), fmt.Sprintf("attempt %d", i))
 			}
 		})
+
+		It("should re-download and remove the old file when the URL changes", func() {
+			oldContent, newContent := []byte("old kernel"), []byte("new kernel")
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/new/linux" {
+					_, _ = w.Write(newContent)
+					return
+				}
+				_, _ = w.Write(oldContent)
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "spec-url-change"
+			createArtifact(name, serverURL+"/old/vmlinuz", sha256Hex(oldContent))
+			defer deleteArtifact(name)
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			status := getStatus(name)
+			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			Expect(status.ObservedGeneration).To(Equal(int64(1)))
+
+			updateSpec(name, func(spec *isobootgithubiov1alpha1.BootArtifactSpec) {
+				spec.URL = serverURL + "/new/linux"
+				spec.SHA256 = new(sha256Hex(newContent))
+			})
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			status = getStatus(name)
+			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			Expect(status.ObservedGeneration).To(Equal(int64(2)))
+
+			data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "linux"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(newContent))
+			_, err = os.Stat(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("should keep the old file until a changed hash is downloaded", func() {
+			oldContent, newContent := []byte("old kernel"), []byte("new kernel")
+			available := false
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if !available {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write(newContent)
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "spec-hash-change"
+			filePath := filepath.Join(dataDir, "artifacts", name, "vmlinuz")
+			Expect(os.MkdirAll(filepath.Dir(filePath), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filePath, oldContent, 0o644)).To(Succeed())
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(oldContent))
+			defer deleteArtifact(name)
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+
+			updateSpec(name, func(spec *isobootgithubiov1alpha1.BootArtifactSpec) {
+				spec.SHA256 = new(sha256Hex(newContent))
+			})
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+			data, err := os.ReadFile(filePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(oldContent))
+
+			available = true
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			data, err = os.ReadFile(filePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(newContent))
+		})
+
+		It("should remove the artifact directory when the BootArtifact is deleted", func() {
+			content := []byte("kernel")
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(content) })
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			name := "finalize"
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(content))
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+
+			var a isobootgithubiov1alpha1.BootArtifact
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)).To(Succeed())
+			Expect(a.Finalizers).To(ContainElement(bootArtifactFinalizer))
+			Expect(k8sClient.Delete(ctx, &a)).To(Succeed())
+
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = os.Stat(filepath.Join(dataDir, "artifacts", name))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should download identical content once and keep it until unreferenced", func() {
+			content := []byte("shared iso")
+			var requests atomic.Int32
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				_, _ = w.Write(content)
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			createArtifact("dedup-a", serverURL+"/a/test.iso", sha256Hex(content))
+			defer deleteArtifact("dedup-a")
+			createArtifact("dedup-b", serverURL+"/b/test.iso", sha256Hex(content))
+			defer deleteArtifact("dedup-b")
+
+			for _, name := range []string{"dedup-a", "dedup-b"} {
+				_, err := doReconcile(name)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			}
+			Expect(requests.Load()).To(Equal(int32(1)))
+
+			blob := filepath.Join(dataDir, "blobs", "sha256", sha256Hex(content))
+			blobInfo, err := os.Stat(blob)
+			Expect(err).NotTo(HaveOccurred())
+			for _, name := range []string{"dedup-a", "dedup-b"} {
+				info, err := os.Stat(filepath.Join(dataDir, "artifacts", name, "test.iso"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.SameFile(info, blobInfo)).To(BeTrue(), name)
+			}
+
+			deleteArtifact("dedup-a")
+			_, err = os.Stat(blob)
+			Expect(err).NotTo(HaveOccurred())
+
+			deleteArtifact("dedup-b")
+			_, err = os.Stat(blob)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("should report progress in status while downloading", func() {
+			content := bytes.Repeat([]byte("x"), 64<<10)
+			release := make(chan struct{})
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				_, _ = w.Write(content[:len(content)/2])
+				w.(http.Flusher).Flush()
+				<-release
+				_, _ = w.Write(content[len(content)/2:])
+			})
+			defer cleanup()
+			releaseOnce := sync.OnceFunc(func() { close(release) })
+			defer releaseOnce()
+			reconciler.HTTPClient = httpClient
+			reconciler.ProgressInterval = time.Nanosecond
+
+			name := "dl-progress"
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(content))
+			defer deleteArtifact(name)
+
+			done := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				_, err := doReconcile(name)
+				done <- err
+			}()
+
+			Eventually(func() int64 {
+				if p := getStatus(name).Progress; p != nil {
+					return p.ReceivedBytes
+				}
+				return 0
+			}).Should(BeNumerically(">=", len(content)/2))
+			progress := getStatus(name).Progress
+			Expect(progress.TotalBytes).To(Equal(int64(len(content))))
+			Expect(progress.Percent).To(BeNumerically(">=", 50))
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseDownloading))
+
+			releaseOnce()
+			Eventually(done).Should(Receive(BeNil()))
+			status := getStatus(name)
+			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			Expect(status.Progress).To(BeNil())
+		})
+
+		It("should queue the download while no slot is free", func() {
+			content := []byte("queued kernel")
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(content)
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+			reconciler.Scheduler = NewDownloadScheduler(1, 0)
+
+			other := types.NamespacedName{Name: "other", Namespace: "default"}
+			ok, _ := reconciler.Scheduler.TryAcquire(other, 0)
+			Expect(ok).To(BeTrue())
+
+			name := "dl-queued"
+			createArtifact(name, serverURL+"/vmlinuz", sha256Hex(content))
+			defer deleteArtifact(name)
+
+			result, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			status := getStatus(name)
+			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseQueued))
+			Expect(status.Message).To(ContainSubstring("position 1"))
+			_, err = os.Stat(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+
+			// Freeing the slot wakes the queued artifact.
+			reconciler.Scheduler.Release(other)
+			var wakeup event.GenericEvent
+			Expect(reconciler.Scheduler.Wakeups()).To(Receive(&wakeup))
+			Expect(wakeup.Object.GetName()).To(Equal(name))
+
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		})
 	})
 })
@@ -0,0 +1,221 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// archiveMember is an entry of a test archive; a non-empty link makes it a
+// symlink.
+type archiveMember struct{ name, content, link string }
+
+// writeTestTarGz writes a gzipped tar archive of members to archivePath.
+func writeTestTarGz(archivePath string, members []archiveMember) error {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	zw := gzip.NewWriter(f)
+	tw := tar.NewWriter(zw)
+	for _, m := range members {
+		hdr := &tar.Header{Name: m.name, Mode: 0o644, Size: int64(len(m.content)), Typeflag: tar.TypeReg}
+		if m.link != "" {
+			hdr = &tar.Header{Name: m.name, Mode: 0o777, Linkname: m.link, Typeflag: tar.TypeSymlink}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(tw, m.content); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeTestZip writes a zip archive of members to archivePath.
+func writeTestZip(archivePath string, members []archiveMember) error {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	zw := zip.NewWriter(f)
+	for _, m := range members {
+		w, err := zw.Create(m.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, m.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+var _ = Describe("BootConfig Controller archive mode", func() {
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootConfigReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-archive-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &BootConfigReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir}
+	})
+	AfterEach(func() { Expect(os.RemoveAll(dataDir)).To(Succeed()) })
+
+	doReconcile := func(name string) {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+		})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	}
+	getStatus := func(name string) isobootgithubiov1alpha1.BootConfigStatus {
+		var bc isobootgithubiov1alpha1.BootConfig
+		ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &bc)).To(Succeed())
+		return bc.Status
+	}
+	readBootFile := func(bcName, name string) string {
+		data, err := os.ReadFile(filepath.Join(dataDir, "boot", bcName, name))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	// readyArchive creates a Ready BootArtifact for filename and writes the
+	// archive with write.
+	readyArchive := func(name, filename string, write func(string) error) {
+		a := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/" + filename, SHA256: new(validSHA256)},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, a)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, a) })
+		a.Status.Phase = isobootgithubiov1alpha1.BootArtifactPhaseReady
+		ExpectWithOffset(1, k8sClient.Status().Update(ctx, a)).To(Succeed())
+		dir := filepath.Join(dataDir, "artifacts", name)
+		ExpectWithOffset(1, os.MkdirAll(dir, 0o755)).To(Succeed())
+		ExpectWithOffset(1, write(filepath.Join(dir, filename))).To(Succeed())
+	}
+
+	makeArchiveConfig := func(name string, archive *isobootgithubiov1alpha1.BootConfigArchiveSpec) {
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       isobootgithubiov1alpha1.BootConfigSpec{Archive: archive},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, bc)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, bc) })
+	}
+
+	netboot := []archiveMember{
+		{name: "./version.info", content: "Debian version: 13"},
+		{name: "./debian-installer/amd64/linux", content: "KERNEL-BYTES"},
+		{name: "./debian-installer/amd64/initrd.gz", content: "INITRD-BYTES"},
+		{name: "./debian-installer/amd64/grub/grub.cfg", content: "GRUB"},
+		{name: "./pxelinux.0", link: "debian-installer/amd64/pxelinux.0"},
+	}
+
+	It("extracts members of a netboot tarball", func() {
+		readyArchive("netboot-tgz", "netboot.tar.gz", func(p string) error { return writeTestTarGz(p, netboot) })
+		makeArchiveConfig("archive-bc-tgz", &isobootgithubiov1alpha1.BootConfigArchiveSpec{
+			ArtifactRef: "netboot-tgz",
+			KernelPath:  "debian-installer/amd64/linux",
+			InitrdPath:  "/debian-installer/amd64/initrd.gz",
+			ExtraFiles:  []isobootgithubiov1alpha1.BootConfigISOFile{{Path: "debian-installer/amd64/grub/grub.cfg", As: "grub.cfg"}},
+		})
+
+		doReconcile("archive-bc-tgz")
+		Expect(getStatus("archive-bc-tgz").Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+		Expect(readBootFile("archive-bc-tgz", "vmlinuz")).To(Equal("KERNEL-BYTES"))
+		Expect(readBootFile("archive-bc-tgz", "initrd")).To(Equal("INITRD-BYTES"))
+		Expect(readBootFile("archive-bc-tgz", "grub.cfg")).To(Equal("GRUB"))
+		Expect(filepath.Join(dataDir, "boot", "archive-bc-tgz", "version.info")).NotTo(BeAnExistingFile())
+
+		// A changed member path is extracted again.
+		var bc isobootgithubiov1alpha1.BootConfig
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "archive-bc-tgz", Namespace: "default"}, &bc)).To(Succeed())
+		bc.Spec.Archive.KernelPath = "version.info"
+		Expect(k8sClient.Update(ctx, &bc)).To(Succeed())
+		doReconcile("archive-bc-tgz")
+		Expect(readBootFile("archive-bc-tgz", "vmlinuz")).To(Equal("Debian version: 13"))
+	})
+
+	It("extracts members of a zip archive with an explicit format", func() {
+		readyArchive("netboot-zip", "netboot.bin", func(p string) error {
+			return writeTestZip(p, []archiveMember{{name: "boot/linux", content: "KERNEL-BYTES"}, {name: "boot/initrd", content: "INITRD-BYTES"}})
+		})
+		makeArchiveConfig("archive-bc-zip", &isobootgithubiov1alpha1.BootConfigArchiveSpec{
+			ArtifactRef: "netboot-zip", Format: isobootgithubiov1alpha1.BootConfigArchiveFormatZip,
+			KernelPath: "boot/linux", InitrdPath: "boot/initrd",
+		})
+
+		doReconcile("archive-bc-zip")
+		Expect(getStatus("archive-bc-zip").Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+		Expect(readBootFile("archive-bc-zip", "vmlinuz")).To(Equal("KERNEL-BYTES"))
+		Expect(readBootFile("archive-bc-zip", "initrd")).To(Equal("INITRD-BYTES"))
+	})
+
+	It("is Error for missing, non-regular or unsafe members", func() {
+		readyArchive("netboot-bad", "netboot.tar.gz", func(p string) error { return writeTestTarGz(p, netboot) })
+		for name, spec := range map[string]isobootgithubiov1alpha1.BootConfigArchiveSpec{
+			"archive-bc-missing":   {KernelPath: "debian-installer/amd64/nope", InitrdPath: "debian-installer/amd64/initrd.gz"},
+			"archive-bc-symlink":   {KernelPath: "pxelinux.0", InitrdPath: "debian-installer/amd64/initrd.gz"},
+			"archive-bc-traversal": {KernelPath: "../../etc/passwd", InitrdPath: "debian-installer/amd64/initrd.gz"},
+		} {
+			spec.ArtifactRef = "netboot-bad"
+			makeArchiveConfig(name, &spec)
+			doReconcile(name)
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseError), name)
+		}
+		Expect(getStatus("archive-bc-symlink").Message).To(ContainSubstring("not a regular file"))
+	})
+
+	It("rejects a BootConfig with more than one mode", func() {
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "archive-bc-two-modes", Namespace: "default"},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				ISO: &isobootgithubiov1alpha1.BootConfigISOSpec{ArtifactRef: "iso"},
+				Archive: &isobootgithubiov1alpha1.BootConfigArchiveSpec{
+					ArtifactRef: "netboot", KernelPath: "linux", InitrdPath: "initrd.gz",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, bc)).To(MatchError(ContainSubstring("exactly one of netboot, iso, archive or diskImage")))
+	})
+})
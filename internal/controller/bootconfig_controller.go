@@ -17,10 +17,13 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -28,6 +31,7 @@ import (
 
 	diskfs "github.com/diskfs/go-diskfs"
 	"github.com/diskfs/go-diskfs/filesystem"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -49,6 +53,7 @@ type BootConfigReconciler struct {
 // +kubebuilder:rbac:groups=isoboot.github.io,resources=bootconfigs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=isoboot.github.io,resources=bootconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=isoboot.github.io,resources=bootartifacts,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get
 
 func (r *BootConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
@@ -71,12 +76,22 @@ func (r *BootConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return r.reconcileISO(ctx, &bc)
 	}
 
+	// Archive and disk image modes: extract kernel and initrd from a tar or
+	// zip archive, or from a partition of a disk image.
+	if bc.Spec.Archive != nil {
+		return r.reconcileArchive(ctx, &bc)
+	}
+	if bc.Spec.DiskImage != nil {
+		return r.reconcileDiskImage(ctx, &bc)
+	}
+
 	// Mode A: direct kernel and initrd refs.
 	if bc.Spec.Netboot == nil {
-		log.V(1).Info("Skipping BootConfig without netboot or iso")
+		log.V(1).Info("Skipping BootConfig without a mode")
 		return ctrl.Result{}, nil
 	}
 	nb := bc.Spec.Netboot
+	bc.Status.ISO = nil
 
 	// Look up referenced BootArtifacts
 	kernelArtifact, err := r.getArtifact(ctx, nb.KernelRef, bc.Namespace)
@@ -84,7 +99,7 @@ func (r *BootConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if client.IgnoreNotFound(err) != nil {
 			return ctrl.Result{}, err
 		}
-		return r.setError(ctx, &bc, fmt.Sprintf("kernel artifact %q not found", nb.KernelRef))
+		return r.setError(ctx, &bc, reasonArtifactMissing, fmt.Sprintf("kernel artifact %q not found", nb.KernelRef))
 	}
 
 	initrdArtifact, err := r.getArtifact(ctx, nb.InitrdRef, bc.Namespace)
@@ -92,15 +107,15 @@ func (r *BootConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if client.IgnoreNotFound(err) != nil {
 			return ctrl.Result{}, err
 		}
-		return r.setError(ctx, &bc, fmt.Sprintf("initrd artifact %q not found", nb.InitrdRef))
+		return r.setError(ctx, &bc, reasonArtifactMissing, fmt.Sprintf("initrd artifact %q not found", nb.InitrdRef))
 	}
 
 	// Check if all artifacts are Ready
 	if kernelArtifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady {
-		return r.setPending(ctx, &bc, fmt.Sprintf("waiting for kernel artifact %q to be Ready", nb.KernelRef))
+		return r.setPending(ctx, &bc, reasonArtifactPending, fmt.Sprintf("waiting for kernel artifact %q to be Ready", nb.KernelRef))
 	}
 	if initrdArtifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady {
-		return r.setPending(ctx, &bc, fmt.Sprintf("waiting for initrd artifact %q to be Ready", nb.InitrdRef))
+		return r.setPending(ctx, &bc, reasonArtifactPending, fmt.Sprintf("waiting for initrd artifact %q to be Ready", nb.InitrdRef))
 	}
 
 	// Optionally look up firmware artifact
@@ -111,50 +126,74 @@ func (r *BootConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			if client.IgnoreNotFound(err) != nil {
 				return ctrl.Result{}, err
 			}
-			return r.setError(ctx, &bc, fmt.Sprintf("firmware artifact %q not found", nb.FirmwareRef))
+			return r.setError(ctx, &bc, reasonArtifactMissing, fmt.Sprintf("firmware artifact %q not found", nb.FirmwareRef))
 		}
 		if firmwareArtifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady {
-			return r.setPending(ctx, &bc, fmt.Sprintf("waiting for firmware artifact %q to be Ready", nb.FirmwareRef))
+			return r.setPending(ctx, &bc, reasonArtifactPending, fmt.Sprintf("waiting for firmware artifact %q to be Ready", nb.FirmwareRef))
 		}
 	}
 
 	// Assemble boot directory with symlinks
 	bootDir := filepath.Join(r.DataDir, "boot", bc.Name)
 
-	kernelFilename := urlutil.FilenameFromURL(kernelArtifact.Spec.URL)
-	initrdFilename := urlutil.FilenameFromURL(initrdArtifact.Spec.URL)
+	kernelFilename := urlutil.ArtifactFilename(kernelArtifact.Spec.URL, string(kernelArtifact.Spec.Decompress))
+	initrdFilename := urlutil.ArtifactFilename(initrdArtifact.Spec.URL, string(initrdArtifact.Spec.Decompress))
 
 	kernelDir := filepath.Join(bootDir, "kernel")
 	initrdDir := filepath.Join(bootDir, "initrd")
 
 	if err := os.MkdirAll(kernelDir, 0o755); err != nil {
-		return r.setError(ctx, &bc, fmt.Sprintf("creating kernel dir: %v", err))
+		return r.setError(ctx, &bc, reasonAssemblyFailed, fmt.Sprintf("creating kernel dir: %v", err))
 	}
 	if err := os.MkdirAll(initrdDir, 0o755); err != nil {
-		return r.setError(ctx, &bc, fmt.Sprintf("creating initrd dir: %v", err))
+		return r.setError(ctx, &bc, reasonAssemblyFailed, fmt.Sprintf("creating initrd dir: %v", err))
 	}
 
 	// Create kernel symlink
 	kernelTarget := filepath.Join("..", "..", "..", "artifacts", kernelArtifact.Name, kernelFilename)
 	if err := ensureSymlink(kernelDir, kernelFilename, kernelTarget); err != nil {
-		return r.setError(ctx, &bc, fmt.Sprintf("creating kernel symlink: %v", err))
+		return r.setError(ctx, &bc, reasonAssemblyFailed, fmt.Sprintf("creating kernel symlink: %v", err))
 	}
 
+	overlay, err := r.buildInitrdOverlay(ctx, &bc, bootDir)
+	if err != nil {
+		return r.setOverlayFailed(ctx, &bc, err)
+	}
+
+	// Firmware and the initrd overlay are appended to a copy of the initrd
+	initrdParts := []string{filepath.Join(r.DataDir, "artifacts", initrdArtifact.Name, initrdFilename)}
+	stamp := sourceStamp(initrdArtifact)
 	if firmwareArtifact != nil {
-		// Firmware mode: concatenate initrd + firmware into initrd dir
-		firmwareFilename := urlutil.FilenameFromURL(firmwareArtifact.Spec.URL)
-		initrdPath := filepath.Join(r.DataDir, "artifacts", initrdArtifact.Name, initrdFilename)
-		firmwarePath := filepath.Join(r.DataDir, "artifacts", firmwareArtifact.Name, firmwareFilename)
+		firmwareFilename := urlutil.ArtifactFilename(firmwareArtifact.Spec.URL, string(firmwareArtifact.Spec.Decompress))
+		initrdParts = append(initrdParts, filepath.Join(r.DataDir, "artifacts", firmwareArtifact.Name, firmwareFilename))
+		stamp = sourceStamp(initrdArtifact, firmwareArtifact)
+	}
+	if overlay != "" {
+		initrdParts = append(initrdParts, overlay)
+	}
+	if len(initrdParts) > 1 {
 		combinedPath := filepath.Join(initrdDir, initrdFilename)
-
-		if err := concatenateFiles(combinedPath, initrdPath, firmwarePath); err != nil {
-			return r.setError(ctx, &bc, fmt.Sprintf("concatenating initrd + firmware: %v", err))
+		current := stampCurrent(bootDir, stamp)
+		if !current {
+			// The artifacts' modification times cannot tell concatenateFiles
+			// that their contents changed.
+			if err := os.Remove(combinedPath); err != nil && !os.IsNotExist(err) {
+				return r.setError(ctx, &bc, reasonAssemblyFailed, fmt.Sprintf("removing stale initrd: %v", err))
+			}
+		}
+		if err := concatenateFiles(combinedPath, initrdParts...); err != nil {
+			return r.setError(ctx, &bc, reasonAssemblyFailed, fmt.Sprintf("concatenating initrd: %v", err))
+		}
+		if !current {
+			if err := writeStamp(bootDir, stamp); err != nil {
+				return r.setError(ctx, &bc, reasonAssemblyFailed, fmt.Sprintf("recording initrd digests: %v", err))
+			}
 		}
 	} else {
-		// No firmware: symlink initrd directly
+		// No firmware or overlay: symlink initrd directly
 		initrdTarget := filepath.Join("..", "..", "..", "artifacts", initrdArtifact.Name, initrdFilename)
 		if err := ensureSymlink(initrdDir, initrdFilename, initrdTarget); err != nil {
-			return r.setError(ctx, &bc, fmt.Sprintf("creating initrd symlink: %v", err))
+			return r.setError(ctx, &bc, reasonAssemblyFailed, fmt.Sprintf("creating initrd symlink: %v", err))
 		}
 	}
 
@@ -165,17 +204,17 @@ func (r *BootConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return r.setReady(ctx, &bc)
 }
 
-// reconcileISO handles Mode B: extract kernel and initrd from an ISO artifact
-// into the boot directory as real files.
+// reconcileISO handles Mode B: extract kernel, initrd and any extra files
+// from an ISO artifact into the boot directory as real files.
 func (r *BootConfigReconciler) reconcileISO(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 	iso := bc.Spec.ISO
 
-	if !isSafeISOPath(iso.KernelPath) {
-		return r.setError(ctx, bc, fmt.Sprintf("invalid kernelPath %q: path traversal not allowed", iso.KernelPath))
+	if iso.KernelPath != "" && !isSafeISOPath(iso.KernelPath) {
+		return r.setError(ctx, bc, reasonInvalidPath, fmt.Sprintf("invalid kernelPath %q: path traversal not allowed", iso.KernelPath))
 	}
-	if !isSafeISOPath(iso.InitrdPath) {
-		return r.setError(ctx, bc, fmt.Sprintf("invalid initrdPath %q: path traversal not allowed", iso.InitrdPath))
+	if iso.InitrdPath != "" && !isSafeISOPath(iso.InitrdPath) {
+		return r.setError(ctx, bc, reasonInvalidPath, fmt.Sprintf("invalid initrdPath %q: path traversal not allowed", iso.InitrdPath))
 	}
 
 	isoArtifact, err := r.getArtifact(ctx, iso.ArtifactRef, bc.Namespace)
@@ -183,22 +222,48 @@ func (r *BootConfigReconciler) reconcileISO(ctx context.Context, bc *isobootgith
 		if client.IgnoreNotFound(err) != nil {
 			return ctrl.Result{}, err
 		}
-		return r.setError(ctx, bc, fmt.Sprintf("iso artifact %q not found", iso.ArtifactRef))
+		return r.setError(ctx, bc, reasonArtifactMissing, fmt.Sprintf("iso artifact %q not found", iso.ArtifactRef))
 	}
 	if isoArtifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady {
-		return r.setPending(ctx, bc, fmt.Sprintf("waiting for iso artifact %q to be Ready", iso.ArtifactRef))
+		return r.setPending(ctx, bc, reasonArtifactPending, fmt.Sprintf("waiting for iso artifact %q to be Ready", iso.ArtifactRef))
 	}
 
-	isoFilename := urlutil.FilenameFromURL(isoArtifact.Spec.URL)
+	isoFilename := urlutil.ArtifactFilename(isoArtifact.Spec.URL, string(isoArtifact.Spec.Decompress))
 	isoPath := filepath.Join(r.DataDir, "artifacts", isoArtifact.Name, isoFilename)
 	bootDir := filepath.Join(r.DataDir, "boot", bc.Name)
 
 	if err := os.MkdirAll(bootDir, 0o755); err != nil {
-		return r.setError(ctx, bc, fmt.Sprintf("creating boot dir: %v", err))
+		return r.setError(ctx, bc, reasonAssemblyFailed, fmt.Sprintf("creating boot dir: %v", err))
 	}
 
-	if err := extractFromISO(isoPath, iso.KernelPath, iso.InitrdPath, bootDir); err != nil {
-		return r.setError(ctx, bc, fmt.Sprintf("extracting from iso: %v", err))
+	extra, err := isoExtraFiles(iso.ExtraFiles, isoFilename)
+	if err != nil {
+		return r.setError(ctx, bc, reasonInvalidPath, err.Error())
+	}
+	stamp := sourceStamp(isoArtifact)
+	current := bc.Status.Phase == isobootgithubiov1alpha1.BootConfigPhaseReady && bc.Status.ObservedGeneration == bc.Generation &&
+		stampCurrent(bootDir, stamp)
+	detected, err := extractFromISO(isoPath, bootDir, iso, bc.Status.ISO, extra, current)
+	if errors.Is(err, errBootFilesNotFound) {
+		bc.Status.ISO = detected
+		return r.setError(ctx, bc, reasonDetectionFailed,
+			fmt.Sprintf("%v in iso %q; set spec.iso.kernelPath and spec.iso.initrdPath", err, isoFilename))
+	}
+	if err != nil {
+		return r.setError(ctx, bc, reasonExtractFailed, fmt.Sprintf("extracting from iso: %v", err))
+	}
+	isoChanged := !equality.Semantic.DeepEqual(bc.Status.ISO, detected)
+	bc.Status.ISO = detected
+	if !current {
+		if err := writeStamp(bootDir, stamp); err != nil {
+			return r.setError(ctx, bc, reasonAssemblyFailed, fmt.Sprintf("recording iso digest: %v", err))
+		}
+	}
+	if err := pruneExtraFiles(bootDir, extra); err != nil {
+		return r.setError(ctx, bc, reasonAssemblyFailed, fmt.Sprintf("removing stale extra files: %v", err))
+	}
+	if err := r.overlayExtractedInitrd(ctx, bc, bootDir); err != nil {
+		return r.setOverlayFailed(ctx, bc, err)
 	}
 
 	// Serve the ISO itself (under its own filename) so installers can fetch
@@ -206,11 +271,91 @@ func (r *BootConfigReconciler) reconcileISO(ctx context.Context, bc *isobootgith
 	// the extracted vmlinuz/initrd.
 	isoTarget := filepath.Join("..", "..", "artifacts", isoArtifact.Name, isoFilename)
 	if err := ensureFileSymlink(filepath.Join(bootDir, isoFilename), isoTarget); err != nil {
-		return r.setError(ctx, bc, fmt.Sprintf("creating iso symlink: %v", err))
+		return r.setError(ctx, bc, reasonAssemblyFailed, fmt.Sprintf("creating iso symlink: %v", err))
 	}
 
 	if bc.Status.Phase != isobootgithubiov1alpha1.BootConfigPhaseReady {
-		log.Info("BootConfig assembled from ISO", "name", bc.Name, "bootDir", bootDir)
+		log.Info("BootConfig assembled from ISO", "name", bc.Name, "bootDir", bootDir,
+			"kernelPath", detected.KernelPath, "initrdPath", detected.InitrdPath)
+	} else if isoChanged && bc.Status.ObservedGeneration == bc.Generation {
+		// setReady only writes a change of phase or generation.
+		if err := r.Status().Update(ctx, bc); err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+		}
+	}
+	return r.setReady(ctx, bc)
+}
+
+// extraction describes a BootConfig mode that extracts its kernel, initrd
+// and extra files from a single artifact, such as archive or diskImage.
+type extraction struct {
+	// kind names the artifact in messages, e.g. "archive".
+	kind                                string
+	artifactRef, kernelPath, initrdPath string
+	extraFiles                          []isobootgithubiov1alpha1.BootConfigISOFile
+	// extract writes files from the artifact at artifactPath into bootDir.
+	// With current set, it may skip outputs that exist; it is unset after a
+	// spec change, since a source path may have changed, and when the
+	// artifact's digest changed.
+	extract func(artifactPath, bootDir string, files []isoFile, current bool) error
+}
+
+// reconcileExtraction assembles the boot directory of an extraction mode,
+// with the same checks and status handling as reconcileISO.
+func (r *BootConfigReconciler) reconcileExtraction(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig, e extraction) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	bc.Status.ISO = nil
+
+	if !isSafeISOPath(e.kernelPath) {
+		return r.setError(ctx, bc, reasonInvalidPath, fmt.Sprintf("invalid kernelPath %q: path traversal not allowed", e.kernelPath))
+	}
+	if !isSafeISOPath(e.initrdPath) {
+		return r.setError(ctx, bc, reasonInvalidPath, fmt.Sprintf("invalid initrdPath %q: path traversal not allowed", e.initrdPath))
+	}
+	extra, err := isoExtraFiles(e.extraFiles)
+	if err != nil {
+		return r.setError(ctx, bc, reasonInvalidPath, err.Error())
+	}
+
+	artifact, err := r.getArtifact(ctx, e.artifactRef, bc.Namespace)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		return r.setError(ctx, bc, reasonArtifactMissing, fmt.Sprintf("%s artifact %q not found", e.kind, e.artifactRef))
+	}
+	if artifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady {
+		return r.setPending(ctx, bc, reasonArtifactPending, fmt.Sprintf("waiting for %s artifact %q to be Ready", e.kind, e.artifactRef))
+	}
+
+	filename := urlutil.ArtifactFilename(artifact.Spec.URL, string(artifact.Spec.Decompress))
+	artifactPath := filepath.Join(r.DataDir, "artifacts", artifact.Name, filename)
+	bootDir := filepath.Join(r.DataDir, "boot", bc.Name)
+	if err := os.MkdirAll(bootDir, 0o755); err != nil {
+		return r.setError(ctx, bc, reasonAssemblyFailed, fmt.Sprintf("creating boot dir: %v", err))
+	}
+
+	files := append([]isoFile{{src: e.kernelPath, name: "vmlinuz"}, {src: e.initrdPath, name: "initrd"}}, extra...)
+	stamp := sourceStamp(artifact)
+	current := bc.Status.Phase == isobootgithubiov1alpha1.BootConfigPhaseReady && bc.Status.ObservedGeneration == bc.Generation &&
+		stampCurrent(bootDir, stamp)
+	if err := e.extract(artifactPath, bootDir, files, current); err != nil {
+		return r.setError(ctx, bc, reasonExtractFailed, fmt.Sprintf("extracting from %s: %v", e.kind, err))
+	}
+	if !current {
+		if err := writeStamp(bootDir, stamp); err != nil {
+			return r.setError(ctx, bc, reasonAssemblyFailed, fmt.Sprintf("recording %s digest: %v", e.kind, err))
+		}
+	}
+	if err := pruneExtraFiles(bootDir, extra); err != nil {
+		return r.setError(ctx, bc, reasonAssemblyFailed, fmt.Sprintf("removing stale extra files: %v", err))
+	}
+	if err := r.overlayExtractedInitrd(ctx, bc, bootDir); err != nil {
+		return r.setOverlayFailed(ctx, bc, err)
+	}
+
+	if bc.Status.Phase != isobootgithubiov1alpha1.BootConfigPhaseReady {
+		log.Info("BootConfig assembled from "+e.kind, "name", bc.Name, "bootDir", bootDir)
 	}
 	return r.setReady(ctx, bc)
 }
@@ -234,56 +379,160 @@ func isSafeISOPath(p string) bool {
 	return p != "" && !slices.Contains(strings.Split(p, "/"), "..")
 }
 
-// extractFromISO opens the ISO9660 image at isoPath and writes the files at
-// kernelPath and initrdPath to outputDir/vmlinuz and outputDir/initrd. It skips
-// the work when both outputs already exist and are newer than the ISO.
-func extractFromISO(isoPath, kernelPath, initrdPath, outputDir string) error {
-	isoInfo, err := os.Stat(isoPath)
-	if err != nil {
-		return fmt.Errorf("stat iso %q: %w", isoPath, err)
+// isoFile is a file extracted from an ISO or archive: src is its path
+// within the ISO or archive and name its slash-separated path relative to the
+// boot directory.
+type isoFile struct{ src, name string }
+
+// extraFilesManifest lists the extra files extracted into a boot directory,
+// one per line, so those dropped from extraFiles can be removed.
+const extraFilesManifest = ".extra-files"
+
+// isoExtraFiles returns the extraFiles entries of spec.iso, spec.archive or
+// spec.diskImage, rejecting source paths with traversal and names that leave
+// the boot directory, repeat, or would replace the kernel, initrd or the
+// other reserved files and directories served next to them.
+func isoExtraFiles(extra []isobootgithubiov1alpha1.BootConfigISOFile, reserved ...string) ([]isoFile, error) {
+	reserved = append(reserved, "vmlinuz", "initrd", extraFilesManifest, sourceStampFile,
+		initrdOverlayFile, initrdOverlayDigestFile, isobootgithubiov1alpha1.OverlaidInitrdDir)
+	files := make([]isoFile, 0, len(extra))
+	for _, f := range extra {
+		if !isSafeISOPath(f.Path) {
+			return nil, fmt.Errorf("invalid extraFiles path %q: path traversal not allowed", f.Path)
+		}
+		name := f.Name()
+		if !filepath.IsLocal(name) || path.Clean(name) != name {
+			return nil, fmt.Errorf("invalid extraFiles name %q: must be a clean relative path", name)
+		}
+		if slices.ContainsFunc(reserved, func(r string) bool { return name == r || strings.HasPrefix(name, r+"/") }) {
+			return nil, fmt.Errorf("invalid extraFiles name %q: reserved", name)
+		}
+		if slices.ContainsFunc(files, func(e isoFile) bool { return e.name == name }) {
+			return nil, fmt.Errorf("invalid extraFiles name %q: duplicate", name)
+		}
+		files = append(files, isoFile{src: f.Path, name: name})
 	}
+	return files, nil
+}
 
-	targets := []struct{ src, dst string }{
-		{kernelPath, filepath.Join(outputDir, "vmlinuz")},
-		{initrdPath, filepath.Join(outputDir, "initrd")},
-	}
-	if upToDate(targets[0].dst, isoInfo.ModTime()) && upToDate(targets[1].dst, isoInfo.ModTime()) {
-		return nil // already extracted and current
-	}
+// errBootFilesNotFound is returned by extractFromISO when spec.iso leaves
+// out a boot file and the ISO does not name one.
+var errBootFilesNotFound = errors.New("no kernel and initrd found")
+
+// extractFromISO extracts the kernel, initrd and extra files of iso from the
+// ISO9660 image at isoPath into outputDir and returns the ISO's status.
+// Boot file paths missing from iso are detected by inspectISO. prev is the
+// status of the last extraction and current reports whether the outputs
+// were extracted from this ISO's contents for the BootConfig's current
+// spec: while they were, exist and prev's paths agree with iso, the ISO is
+// not opened at all, and only missing outputs are extracted again.
+func extractFromISO(isoPath, outputDir string, iso *isobootgithubiov1alpha1.BootConfigISOSpec,
+	prev *isobootgithubiov1alpha1.BootConfigISOStatus, extra []isoFile, current bool) (*isobootgithubiov1alpha1.BootConfigISOStatus, error) {
+	if _, err := os.Stat(isoPath); err != nil {
+		return nil, fmt.Errorf("stat iso %q: %w", isoPath, err)
+	}
+	stale := func(files []isoFile) bool {
+		return slices.ContainsFunc(files, func(f isoFile) bool {
+			return !extracted(filepath.Join(outputDir, filepath.FromSlash(f.name)))
+		})
+	}
+	if current && prev != nil && prev.KernelPath == cmp.Or(iso.KernelPath, prev.KernelPath) &&
+		prev.InitrdPath == cmp.Or(iso.InitrdPath, prev.InitrdPath) && !stale(append(bootFiles(prev), extra...)) {
+		return prev, nil // already extracted and current
+	}
+	defer func(start time.Time) { isoExtractionDuration.Observe(time.Since(start).Seconds()) }(time.Now())
 
 	disk, err := diskfs.Open(isoPath, diskfs.WithOpenMode(diskfs.ReadOnly))
 	if err != nil {
-		return fmt.Errorf("opening iso %q: %w", isoPath, err)
+		return nil, fmt.Errorf("opening iso %q: %w", isoPath, err)
 	}
 	defer func() { _ = disk.Close() }()
 
 	fsys, err := disk.GetFilesystem(0)
 	if err != nil {
-		return fmt.Errorf("reading iso filesystem: %w", err)
+		return nil, fmt.Errorf("reading iso filesystem: %w", err)
 	}
 
-	for _, e := range targets {
-		if err := extractFile(fsys, e.src, e.dst); err != nil {
-			return err
+	status := inspectISO(fsys)
+	status.KernelPath = cmp.Or(iso.KernelPath, status.KernelPath)
+	status.InitrdPath = cmp.Or(iso.InitrdPath, status.InitrdPath)
+	if status.KernelPath == "" || status.InitrdPath == "" {
+		return status, errBootFilesNotFound
+	}
+	// A kernel or initrd extracted from another path is stale however
+	// recent it is.
+	for i, f := range bootFiles(status) {
+		if prev == nil || bootFiles(prev)[i].src != f.src {
+			if err := os.Remove(filepath.Join(outputDir, f.name)); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("removing stale %q: %w", f.name, err)
+			}
 		}
 	}
-	return nil
+
+	for _, f := range append(bootFiles(status), extra...) {
+		dst := filepath.Join(outputDir, filepath.FromSlash(f.name))
+		if current && extracted(dst) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return nil, fmt.Errorf("creating directory for %q: %w", f.name, err)
+		}
+		if err := extractFile(fsys, f.src, dst); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// bootFiles returns the kernel and initrd extracted for an ISO status.
+func bootFiles(status *isobootgithubiov1alpha1.BootConfigISOStatus) []isoFile {
+	return []isoFile{{src: status.KernelPath, name: "vmlinuz"}, {src: status.InitrdPath, name: "initrd"}}
 }
 
-// upToDate reports whether dst exists and was modified after srcModTime.
-func upToDate(dst string, srcModTime time.Time) bool {
-	info, err := os.Stat(dst)
-	return err == nil && info.ModTime().After(srcModTime)
+// pruneExtraFiles removes the extra files extracted by an earlier reconcile
+// that are no longer wanted, and records the current ones.
+func pruneExtraFiles(bootDir string, extra []isoFile) error {
+	manifestPath := filepath.Join(bootDir, extraFilesManifest)
+	data, err := os.ReadFile(manifestPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	names := make([]string, 0, len(extra))
+	for _, f := range extra {
+		names = append(names, f.name)
+	}
+	for name := range strings.Lines(string(data)) {
+		name = strings.TrimSuffix(name, "\n")
+		if slices.Contains(names, name) || !filepath.IsLocal(name) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(bootDir, filepath.FromSlash(name))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if len(names) == 0 {
+		if err := os.Remove(manifestPath); !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(manifestPath, []byte(strings.Join(names, "\n")+"\n"), 0o644)
 }
 
-// extractFile copies src from the ISO filesystem to dst on disk atomically.
+// extractFile copies src from an ISO or disk image filesystem to dst on disk
+// atomically.
 func extractFile(fsys filesystem.FileSystem, src, dst string) error {
 	f, err := fsys.OpenFile("/"+strings.TrimPrefix(src, "/"), os.O_RDONLY)
 	if err != nil {
-		return fmt.Errorf("opening %q in iso: %w", src, err)
+		return fmt.Errorf("opening %q: %w", src, err)
 	}
 	defer func() { _ = f.Close() }()
+	return writeExtracted(f, src, dst)
+}
 
+// writeExtracted writes the contents of src, read from r, to dst on disk
+// atomically, refusing files larger than maxExtractedFileSize.
+func writeExtracted(r io.Reader, src, dst string) error {
 	tmp, err := os.CreateTemp(filepath.Dir(dst), ".extract-*")
 	if err != nil {
 		return fmt.Errorf("creating temp file: %w", err)
@@ -294,7 +543,7 @@ func extractFile(fsys filesystem.FileSystem, src, dst string) error {
 		_ = os.Remove(tmpPath)
 	}()
 
-	n, err := io.Copy(tmp, io.LimitReader(f, maxExtractedFileSize+1))
+	n, err := io.Copy(tmp, io.LimitReader(r, maxExtractedFileSize+1))
 	if err != nil {
 		return fmt.Errorf("copying %q: %w", src, err)
 	}
@@ -313,20 +562,22 @@ func extractFile(fsys filesystem.FileSystem, src, dst string) error {
 	return nil
 }
 
-// concatenateFiles writes the concatenation of srcA and srcB to dst atomically.
-func concatenateFiles(dst, srcA, srcB string) error {
-	// Check if the concatenated file already exists and is newer than both sources
-	dstInfo, dstErr := os.Stat(dst)
-	if dstErr == nil {
-		srcAInfo, err := os.Stat(srcA)
-		if err != nil {
-			return fmt.Errorf("stat initrd: %w", err)
-		}
-		srcBInfo, err := os.Stat(srcB)
-		if err != nil {
-			return fmt.Errorf("stat firmware: %w", err)
+// concatenateFiles writes the concatenation of srcs to dst atomically.
+func concatenateFiles(dst string, srcs ...string) error {
+	// Check if the concatenated file already exists, is not older than any
+	// source and as long as them together, which it is not once one of them
+	// is dropped. A source written just before it may share its mtime.
+	if dstInfo, err := os.Stat(dst); err == nil {
+		stale, size := false, int64(0)
+		for _, src := range srcs {
+			info, err := os.Stat(src)
+			if err != nil {
+				return fmt.Errorf("stat %s: %w", src, err)
+			}
+			stale = stale || info.ModTime().After(dstInfo.ModTime())
+			size += info.Size()
 		}
-		if dstInfo.ModTime().After(srcAInfo.ModTime()) && dstInfo.ModTime().After(srcBInfo.ModTime()) {
+		if !stale && size == dstInfo.Size() {
 			return nil // already up to date
 		}
 	}
@@ -351,7 +602,7 @@ func concatenateFiles(dst, srcA, srcB string) error {
 		_ = os.Remove(tmpPath) // clean up on error
 	}()
 
-	for _, src := range []string{srcA, srcB} {
+	for _, src := range srcs {
 		f, err := os.Open(src)
 		if err != nil {
 			return fmt.Errorf("opening %s: %w", src, err)
@@ -404,41 +655,51 @@ func ensureSymlink(dir, filename, target string) error {
 }
 
 func (r *BootConfigReconciler) setReady(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig) (ctrl.Result, error) {
-	if bc.Status.Phase == isobootgithubiov1alpha1.BootConfigPhaseReady {
-		return ctrl.Result{}, nil
+	if err := r.setStatus(ctx, bc, isobootgithubiov1alpha1.BootConfigPhaseReady, reasonAssembled, ""); err != nil {
+		return ctrl.Result{}, err
 	}
-	bc.Status.Phase = isobootgithubiov1alpha1.BootConfigPhaseReady
-	bc.Status.Message = ""
-	if err := r.Status().Update(ctx, bc); err != nil {
-		return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+	if hasUnwatchedOverlays(bc) {
+		// ConfigMaps and Secrets are not watched.
+		return ctrl.Result{RequeueAfter: overlayResyncInterval}, nil
 	}
 	return ctrl.Result{}, nil
 }
 
-func (r *BootConfigReconciler) setPending(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig, message string) (ctrl.Result, error) {
-	if bc.Status.Phase == isobootgithubiov1alpha1.BootConfigPhasePending && bc.Status.Message == message {
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
-	}
-	bc.Status.Phase = isobootgithubiov1alpha1.BootConfigPhasePending
-	bc.Status.Message = message
-	if err := r.Status().Update(ctx, bc); err != nil {
-		return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+func (r *BootConfigReconciler) setPending(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig, reason, message string) (ctrl.Result, error) {
+	if err := r.setStatus(ctx, bc, isobootgithubiov1alpha1.BootConfigPhasePending, reason, message); err != nil {
+		return ctrl.Result{}, err
 	}
 	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 }
 
-func (r *BootConfigReconciler) setError(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig, message string) (ctrl.Result, error) {
-	if bc.Status.Phase == isobootgithubiov1alpha1.BootConfigPhaseError && bc.Status.Message == message {
-		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+func (r *BootConfigReconciler) setError(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig, reason, message string) (ctrl.Result, error) {
+	if bc.Status.Phase != isobootgithubiov1alpha1.BootConfigPhaseError || bc.Status.Message != message {
+		logf.FromContext(ctx).Info("BootConfig error", "message", message)
 	}
-	log := logf.FromContext(ctx)
-	log.Info("BootConfig error", "message", message)
-	bc.Status.Phase = isobootgithubiov1alpha1.BootConfigPhaseError
+	if err := r.setStatus(ctx, bc, isobootgithubiov1alpha1.BootConfigPhaseError, reason, message); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+}
+
+// setStatus records phase and message with the conditions derived from them,
+// skipping the write when nothing changed.
+func (r *BootConfigReconciler) setStatus(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig, phase isobootgithubiov1alpha1.BootConfigPhase, reason, message string) error {
+	changed := bc.Status.Phase != phase || bc.Status.Message != message ||
+		bc.Status.ObservedGeneration != bc.Generation
+	bc.Status.Phase = phase
 	bc.Status.Message = message
+	bc.Status.ObservedGeneration = bc.Generation
+	if setBootConfigConditions(bc, reason) {
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
 	if err := r.Status().Update(ctx, bc); err != nil {
-		return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+		return fmt.Errorf("updating status: %w", err)
 	}
-	return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	return nil
 }
 
 func (r *BootConfigReconciler) findBootConfigsForArtifact(ctx context.Context, obj client.Object) []reconcile.Request {
@@ -450,10 +711,7 @@ func (r *BootConfigReconciler) findBootConfigsForArtifact(ctx context.Context, o
 	var requests []reconcile.Request
 	for i := range configs.Items {
 		bc := &configs.Items[i]
-		if (bc.Spec.Netboot != nil && (bc.Spec.Netboot.KernelRef == obj.GetName() ||
-			bc.Spec.Netboot.InitrdRef == obj.GetName() ||
-			bc.Spec.Netboot.FirmwareRef == obj.GetName())) ||
-			(bc.Spec.ISO != nil && bc.Spec.ISO.ArtifactRef == obj.GetName()) {
+		if slices.Contains(ArtifactRefs(bc), obj.GetName()) {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: client.ObjectKeyFromObject(bc),
 			})
@@ -462,6 +720,32 @@ func (r *BootConfigReconciler) findBootConfigsForArtifact(ctx context.Context, o
 	return requests
 }
 
+// ArtifactRefs returns the names of the BootArtifacts a BootConfig uses.
+func ArtifactRefs(bc *isobootgithubiov1alpha1.BootConfig) []string {
+	var refs []string
+	if nb := bc.Spec.Netboot; nb != nil {
+		refs = append(refs, nb.KernelRef, nb.InitrdRef)
+		if nb.FirmwareRef != "" {
+			refs = append(refs, nb.FirmwareRef)
+		}
+	}
+	if bc.Spec.ISO != nil {
+		refs = append(refs, bc.Spec.ISO.ArtifactRef)
+	}
+	if bc.Spec.Archive != nil {
+		refs = append(refs, bc.Spec.Archive.ArtifactRef)
+	}
+	if bc.Spec.DiskImage != nil {
+		refs = append(refs, bc.Spec.DiskImage.ArtifactRef)
+	}
+	for _, o := range bc.Spec.InitrdOverlays {
+		if o.ArtifactRef != "" {
+			refs = append(refs, o.ArtifactRef)
+		}
+	}
+	return refs
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *BootConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
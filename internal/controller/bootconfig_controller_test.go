@@ -23,6 +23,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -240,7 +241,12 @@ var _ = Describe("BootConfig Controller", func() {
 			result, err := doReconcile(bcName)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.RequeueAfter).To(BeZero())
-			Expect(getStatus(bcName).Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+			status := getStatus(bcName)
+			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+			Expect(status.ObservedGeneration).To(Equal(int64(1)))
+			Expect(meta.IsStatusConditionTrue(status.Conditions, isobootgithubiov1alpha1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(status.Conditions, isobootgithubiov1alpha1.ConditionReferencesResolved)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(status.Conditions, isobootgithubiov1alpha1.ConditionDegraded)).To(BeTrue())
 			expectSymlinksReady(bcName, kernelName, initrdName)
 
 			// 2. Delete and reconcile — boot directory should be removed
@@ -284,6 +290,11 @@ var _ = Describe("BootConfig Controller", func() {
 
 			status := getStatus(bcName)
 			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhasePending))
+			Expect(meta.IsStatusConditionTrue(status.Conditions, isobootgithubiov1alpha1.ConditionProgressing)).To(BeTrue())
+			ready := meta.FindStatusCondition(status.Conditions, isobootgithubiov1alpha1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("ArtifactNotReady"))
 		})
 
 		It("should set Error when artifact is missing", func() {
@@ -299,6 +310,11 @@ var _ = Describe("BootConfig Controller", func() {
 			status := getStatus(bcName)
 			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseError))
 			Expect(status.Message).To(ContainSubstring("not found"))
+			Expect(meta.IsStatusConditionTrue(status.Conditions, isobootgithubiov1alpha1.ConditionDegraded)).To(BeTrue())
+			resolved := meta.FindStatusCondition(status.Conditions, isobootgithubiov1alpha1.ConditionReferencesResolved)
+			Expect(resolved).NotTo(BeNil())
+			Expect(resolved.Status).To(Equal(metav1.ConditionFalse))
+			Expect(resolved.Reason).To(Equal("ArtifactNotFound"))
 		})
 
 		It("should remove stale symlinks when ref filename changes", func() {
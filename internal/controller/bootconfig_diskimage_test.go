@@ -0,0 +1,187 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+
+	diskfs "github.com/diskfs/go-diskfs"
+	"github.com/diskfs/go-diskfs/disk"
+	"github.com/diskfs/go-diskfs/filesystem"
+	"github.com/diskfs/go-diskfs/partition/gpt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// writeTestDiskImage writes a GPT disk image to imagePath with one FAT32
+// partition named partName, labelled fsLabel and holding files.
+func writeTestDiskImage(imagePath, partName, fsLabel string, files map[string]string) error {
+	const size = 64 << 20
+	d, err := diskfs.Create(imagePath, size, diskfs.SectorSizeDefault)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = d.Close() }()
+	if err := d.Partition(&gpt.Table{
+		LogicalSectorSize:  512,
+		PhysicalSectorSize: 512,
+		ProtectiveMBR:      true,
+		Partitions: []*gpt.Partition{{
+			Index: 1, Start: 2048, End: size/512 - 2048, Type: gpt.EFISystemPartition, Name: partName,
+		}},
+	}); err != nil {
+		return err
+	}
+	fsys, err := d.CreateFilesystem(disk.FilesystemSpec{Partition: 1, FSType: filesystem.TypeFat32, VolumeLabel: fsLabel})
+	if err != nil {
+		return err
+	}
+	for name, content := range files {
+		if err := fsys.Mkdir("/" + path.Dir(name)); err != nil {
+			return err
+		}
+		f, err := fsys.OpenFile("/"+name, os.O_CREATE|os.O_RDWR)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ = Describe("BootConfig Controller disk image mode", func() {
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootConfigReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-diskimage-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &BootConfigReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir}
+	})
+	AfterEach(func() { Expect(os.RemoveAll(dataDir)).To(Succeed()) })
+
+	doReconcile := func(name string) {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+		})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	}
+	getStatus := func(name string) isobootgithubiov1alpha1.BootConfigStatus {
+		var bc isobootgithubiov1alpha1.BootConfig
+		ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &bc)).To(Succeed())
+		return bc.Status
+	}
+	readBootFile := func(bcName, name string) string {
+		data, err := os.ReadFile(filepath.Join(dataDir, "boot", bcName, name))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	// readyDiskImage creates a Ready BootArtifact holding a Flatcar-like GPT
+	// image with an EFI-SYSTEM partition.
+	readyDiskImage := func(name string) {
+		a := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/disk.img", SHA256: new(validSHA256)},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, a)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, a) })
+		a.Status.Phase = isobootgithubiov1alpha1.BootArtifactPhaseReady
+		ExpectWithOffset(1, k8sClient.Status().Update(ctx, a)).To(Succeed())
+		dir := filepath.Join(dataDir, "artifacts", name)
+		ExpectWithOffset(1, os.MkdirAll(dir, 0o755)).To(Succeed())
+		ExpectWithOffset(1, writeTestDiskImage(filepath.Join(dir, "disk.img"), "EFI-SYSTEM", "ESP", map[string]string{
+			"flatcar/vmlinuz-a": "KERNEL-BYTES",
+			"flatcar/initrd":    "INITRD-BYTES",
+			"EFI/boot/grub.cfg": "GRUB",
+		})).To(Succeed())
+	}
+
+	makeDiskImageConfig := func(name string, di *isobootgithubiov1alpha1.BootConfigDiskImageSpec) {
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       isobootgithubiov1alpha1.BootConfigSpec{DiskImage: di},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, bc)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, bc) })
+	}
+
+	It("extracts files from a partition selected by index or label", func() {
+		readyDiskImage("flatcar-image")
+		for name, di := range map[string]isobootgithubiov1alpha1.BootConfigDiskImageSpec{
+			"diskimage-bc-index":    {Partition: new(int32(1))},
+			"diskimage-bc-gptname":  {PartitionLabel: "EFI-SYSTEM"},
+			"diskimage-bc-fs-label": {PartitionLabel: "ESP"},
+		} {
+			di.ArtifactRef = "flatcar-image"
+			di.KernelPath, di.InitrdPath = "flatcar/vmlinuz-a", "/flatcar/initrd"
+			di.ExtraFiles = []isobootgithubiov1alpha1.BootConfigISOFile{{Path: "EFI/boot/grub.cfg", As: "grub.cfg"}}
+			makeDiskImageConfig(name, &di)
+
+			doReconcile(name)
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady), name)
+			Expect(readBootFile(name, "vmlinuz")).To(Equal("KERNEL-BYTES"))
+			Expect(readBootFile(name, "initrd")).To(Equal("INITRD-BYTES"))
+			Expect(readBootFile(name, "grub.cfg")).To(Equal("GRUB"))
+		}
+	})
+
+	It("is Error for a missing partition or file", func() {
+		readyDiskImage("flatcar-image-bad")
+		for name, di := range map[string]isobootgithubiov1alpha1.BootConfigDiskImageSpec{
+			"diskimage-bc-no-index": {Partition: new(int32(2)), KernelPath: "flatcar/vmlinuz-a"},
+			"diskimage-bc-no-label": {PartitionLabel: "USR-A", KernelPath: "flatcar/vmlinuz-a"},
+			"diskimage-bc-no-file":  {PartitionLabel: "ESP", KernelPath: "flatcar/vmlinuz-b"},
+		} {
+			di.ArtifactRef = "flatcar-image-bad"
+			di.InitrdPath = "flatcar/initrd"
+			makeDiskImageConfig(name, &di)
+			doReconcile(name)
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseError), name)
+		}
+		Expect(getStatus("diskimage-bc-no-label").Message).To(ContainSubstring(`no partition labelled "USR-A"`))
+	})
+
+	It("rejects a disk image with both or neither of partition and partitionLabel", func() {
+		for name, di := range map[string]isobootgithubiov1alpha1.BootConfigDiskImageSpec{
+			"diskimage-bc-both":    {Partition: new(int32(1)), PartitionLabel: "ESP"},
+			"diskimage-bc-neither": {},
+		} {
+			di.ArtifactRef, di.KernelPath, di.InitrdPath = "flatcar-image", "vmlinuz", "initrd"
+			bc := &isobootgithubiov1alpha1.BootConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       isobootgithubiov1alpha1.BootConfigSpec{DiskImage: &di},
+			}
+			Expect(k8sClient.Create(ctx, bc)).To(MatchError(ContainSubstring("exactly one of partition or partitionLabel")), name)
+		}
+	})
+})
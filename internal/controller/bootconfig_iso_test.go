@@ -22,6 +22,8 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
+	"time"
 
 	diskfs "github.com/diskfs/go-diskfs"
 	"github.com/diskfs/go-diskfs/disk"
@@ -196,6 +198,189 @@ var _ = Describe("BootConfig Controller ISO mode", func() {
 		Expect(isoTarget).To(Equal(filepath.Join("..", "..", "artifacts", "iso-idem", "test.iso")))
 	})
 
+	It("re-extracts when the artifact is relinked to an older stored blob", func() {
+		defer readyISOArtifact("iso-relink", contents)()
+		defer makeISOConfig("iso-bc-relink", "iso-relink", "casper/vmlinuz", "casper/initrd")()
+
+		_, err := doReconcile("iso-bc-relink")
+		Expect(err).NotTo(HaveOccurred())
+		kernelPath := filepath.Join(dataDir, "boot", "iso-bc-relink", "vmlinuz")
+		Expect(os.ReadFile(kernelPath)).To(BeEquivalentTo("KERNEL-BYTES"))
+
+		// The artifact now names another ISO already in the blob store: its
+		// file is hardlinked to that blob and older than the extracted files.
+		blob := filepath.Join(dataDir, "blob.iso")
+		Expect(writeTestISO(blob, map[string]string{"casper/vmlinuz": "OTHER-KERNEL", "casper/initrd": "OTHER-INITRD"})).To(Succeed())
+		hourAgo := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(blob, hourAgo, hourAgo)).To(Succeed())
+		Expect(linkFile(blob, filepath.Join(dataDir, "artifacts", "iso-relink", "test.iso"))).To(Succeed())
+		var a isobootgithubiov1alpha1.BootArtifact
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "iso-relink", Namespace: "default"}, &a)).To(Succeed())
+		a.Spec.SHA256 = new(strings.Repeat("b", 64))
+		Expect(k8sClient.Update(ctx, &a)).To(Succeed())
+
+		_, err = doReconcile("iso-bc-relink")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getStatus("iso-bc-relink").Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+		Expect(os.ReadFile(kernelPath)).To(BeEquivalentTo("OTHER-KERNEL"))
+		Expect(os.ReadFile(filepath.Join(dataDir, "boot", "iso-bc-relink", "initrd"))).To(BeEquivalentTo("OTHER-INITRD"))
+	})
+
+	It("extracts extra files and removes those dropped from the spec", func() {
+		defer readyISOArtifact("iso-extra", map[string]string{
+			"images/vmlinuz": "KERNEL-BYTES", "images/initrd": "INITRD-BYTES",
+			"images/install.img": "STAGE2-BYTES", "treeinfo": "TREEINFO", "discinfo": "DISCINFO",
+		})()
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "iso-bc-extra", Namespace: "default"},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				ISO: &isobootgithubiov1alpha1.BootConfigISOSpec{
+					ArtifactRef: "iso-extra", KernelPath: "images/vmlinuz", InitrdPath: "images/initrd",
+					ExtraFiles: []isobootgithubiov1alpha1.BootConfigISOFile{
+						{Path: "images/install.img"},
+						{Path: "/treeinfo", As: ".treeinfo"},
+						{Path: "/discinfo"},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, bc)).To(Succeed())
+		defer func() { _ = k8sClient.Delete(ctx, bc) }()
+
+		_, err := doReconcile("iso-bc-extra")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getStatus("iso-bc-extra").Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+		bootDir := filepath.Join(dataDir, "boot", "iso-bc-extra")
+		stage2, err := os.ReadFile(filepath.Join(bootDir, "images", "install.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(stage2)).To(Equal("STAGE2-BYTES"))
+		treeinfo, err := os.ReadFile(filepath.Join(bootDir, ".treeinfo"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(treeinfo)).To(Equal("TREEINFO"))
+		discinfo, err := os.ReadFile(filepath.Join(bootDir, "discinfo"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(discinfo)).To(Equal("DISCINFO"))
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "iso-bc-extra", Namespace: "default"}, bc)).To(Succeed())
+		bc.Spec.ISO.ExtraFiles = bc.Spec.ISO.ExtraFiles[:1]
+		Expect(k8sClient.Update(ctx, bc)).To(Succeed())
+		_, err = doReconcile("iso-bc-extra")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Join(bootDir, ".treeinfo")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(bootDir, "images", "install.img")).To(BeAnExistingFile())
+	})
+
+	It("re-extracts an extra file whose path changes under the same name", func() {
+		defer readyISOArtifact("iso-extra-path", map[string]string{
+			"images/vmlinuz": "KERNEL-BYTES", "images/initrd": "INITRD-BYTES",
+			"ks/minimal.cfg": "MINIMAL", "ks/server.cfg": "SERVER",
+		})()
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "iso-bc-extra-path", Namespace: "default"},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				ISO: &isobootgithubiov1alpha1.BootConfigISOSpec{
+					ArtifactRef: "iso-extra-path", KernelPath: "images/vmlinuz", InitrdPath: "images/initrd",
+					ExtraFiles: []isobootgithubiov1alpha1.BootConfigISOFile{{Path: "ks/minimal.cfg", As: "ks.cfg"}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, bc)).To(Succeed())
+		defer func() { _ = k8sClient.Delete(ctx, bc) }()
+
+		_, err := doReconcile("iso-bc-extra-path")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getStatus("iso-bc-extra-path").Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+		ks := filepath.Join(dataDir, "boot", "iso-bc-extra-path", "ks.cfg")
+		data, err := os.ReadFile(ks)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("MINIMAL"))
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "iso-bc-extra-path", Namespace: "default"}, bc)).To(Succeed())
+		bc.Spec.ISO.ExtraFiles[0].Path = "ks/server.cfg"
+		Expect(k8sClient.Update(ctx, bc)).To(Succeed())
+		_, err = doReconcile("iso-bc-extra-path")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getStatus("iso-bc-extra-path").Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+		data, err = os.ReadFile(ks)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("SERVER"))
+	})
+
+	It("is Error when an extra file would leave or clobber the boot directory", func() {
+		defer readyISOArtifact("iso-extra-bad", contents)()
+		for name, file := range map[string]isobootgithubiov1alpha1.BootConfigISOFile{
+			"iso-bc-extra-trav":   {Path: "../casper/vmlinuz"},
+			"iso-bc-extra-escape": {Path: "casper/vmlinuz", As: "../../artifacts/x"},
+			"iso-bc-extra-kernel": {Path: "casper/initrd", As: "vmlinuz"},
+			"iso-bc-extra-iso":    {Path: "casper/initrd", As: "test.iso"},
+		} {
+			bc := &isobootgithubiov1alpha1.BootConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec: isobootgithubiov1alpha1.BootConfigSpec{
+					ISO: &isobootgithubiov1alpha1.BootConfigISOSpec{
+						ArtifactRef: "iso-extra-bad", KernelPath: "casper/vmlinuz", InitrdPath: "casper/initrd",
+						ExtraFiles: []isobootgithubiov1alpha1.BootConfigISOFile{file},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, bc)).To(Succeed())
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseError), name)
+			Expect(k8sClient.Delete(ctx, bc)).To(Succeed())
+		}
+	})
+
+	It("detects the kernel and initrd when spec.iso omits them", func() {
+		defer readyISOArtifact("iso-detect", map[string]string{
+			".treeinfo": "[release]\nname = Rocky Linux\nversion = 9.4\n\n[tree]\narch = x86_64\n\n" +
+				"[images-x86_64]\nkernel = images/pxeboot/vmlinuz\ninitrd = images/pxeboot/initrd.img\n",
+			"images/pxeboot/vmlinuz":    "KERNEL-BYTES",
+			"images/pxeboot/initrd.img": "INITRD-BYTES",
+			"images/alt/vmlinuz":        "ALT-KERNEL-BYTES",
+		})()
+		defer makeISOConfig("iso-bc-detect", "iso-detect", "", "")()
+
+		_, err := doReconcile("iso-bc-detect")
+		Expect(err).NotTo(HaveOccurred())
+		status := getStatus("iso-bc-detect")
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+		Expect(status.ISO).NotTo(BeNil())
+		Expect(status.ISO.Distro).To(Equal("Rocky Linux"))
+		Expect(status.ISO.Version).To(Equal("9.4"))
+		Expect(status.ISO.KernelPath).To(Equal("images/pxeboot/vmlinuz"))
+		Expect(status.ISO.InitrdPath).To(Equal("images/pxeboot/initrd.img"))
+		Expect(status.ISO.Files).To(ContainElement("images/"))
+		kernel, err := os.ReadFile(filepath.Join(dataDir, "boot", "iso-bc-detect", "vmlinuz"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(kernel)).To(Equal("KERNEL-BYTES"))
+
+		// A path set in the spec wins and replaces the extracted kernel.
+		var bc isobootgithubiov1alpha1.BootConfig
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "iso-bc-detect", Namespace: "default"}, &bc)).To(Succeed())
+		bc.Spec.ISO.KernelPath = "images/alt/vmlinuz"
+		Expect(k8sClient.Update(ctx, &bc)).To(Succeed())
+		_, err = doReconcile("iso-bc-detect")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getStatus("iso-bc-detect").ISO.KernelPath).To(Equal("images/alt/vmlinuz"))
+		kernel, err = os.ReadFile(filepath.Join(dataDir, "boot", "iso-bc-detect", "vmlinuz"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(kernel)).To(Equal("ALT-KERNEL-BYTES"))
+	})
+
+	It("is Error when the kernel cannot be detected", func() {
+		defer readyISOArtifact("iso-undetected", map[string]string{"README": "hello"})()
+		defer makeISOConfig("iso-bc-undetected", "iso-undetected", "", "")()
+
+		_, err := doReconcile("iso-bc-undetected")
+		Expect(err).NotTo(HaveOccurred())
+		status := getStatus("iso-bc-undetected")
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseError))
+		Expect(status.Message).To(ContainSubstring("spec.iso.kernelPath"))
+		Expect(status.ISO).NotTo(BeNil())
+		Expect(status.ISO.Files).To(ContainElement("README"))
+	})
+
 	It("is Pending when the ISO artifact is not Ready", func() {
 		defer makeISOArtifact("iso-pending", isobootgithubiov1alpha1.BootArtifactPhasePending)()
 		defer makeISOConfig("iso-bc-pending", "iso-pending", "casper/vmlinuz", "casper/initrd")()
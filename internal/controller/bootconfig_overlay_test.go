@@ -0,0 +1,224 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+var _ = Describe("BootConfig Controller initrd overlays", func() {
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootConfigReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-overlay-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &BootConfigReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir}
+	})
+	AfterEach(func() { Expect(os.RemoveAll(dataDir)).To(Succeed()) })
+
+	doReconcile := func(name string) reconcile.Result {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+		})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return result
+	}
+	getConfig := func(name string) *isobootgithubiov1alpha1.BootConfig {
+		var bc isobootgithubiov1alpha1.BootConfig
+		ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &bc)).To(Succeed())
+		return &bc
+	}
+	readFile := func(elem ...string) string {
+		data, err := os.ReadFile(filepath.Join(append([]string{dataDir}, elem...)...))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return string(data)
+	}
+	// readOverlaid splits an initrd into its base, which must be prefix,
+	// and the entries of the overlay appended to it.
+	readOverlaid := func(initrd, prefix string) map[string]cpioEntry {
+		ExpectWithOffset(1, initrd).To(HavePrefix(prefix))
+		_, entries, err := readTestCpio([]byte(strings.TrimPrefix(initrd, prefix)))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return entries
+	}
+
+	// createArtifact creates a BootArtifact in phase and, when Ready, its
+	// file with content.
+	createArtifact := func(name, filename, content string, phase isobootgithubiov1alpha1.BootArtifactPhase) {
+		a := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://example.com/" + filename, SHA256: new(validSHA256)},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, a)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, a) })
+		a.Status.Phase = phase
+		ExpectWithOffset(1, k8sClient.Status().Update(ctx, a)).To(Succeed())
+		if phase == isobootgithubiov1alpha1.BootArtifactPhaseReady {
+			dir := filepath.Join(dataDir, "artifacts", name)
+			ExpectWithOffset(1, os.MkdirAll(dir, 0o755)).To(Succeed())
+			ExpectWithOffset(1, os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644)).To(Succeed())
+		}
+	}
+	createConfigMap := func(name string, data map[string]string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Data: data}
+		ExpectWithOffset(1, k8sClient.Create(ctx, cm)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, cm) })
+		return cm
+	}
+	createConfig := func(name string, spec isobootgithubiov1alpha1.BootConfigSpec) {
+		bc := &isobootgithubiov1alpha1.BootConfig{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Spec: spec}
+		ExpectWithOffset(1, k8sClient.Create(ctx, bc)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, bc) })
+	}
+
+	It("appends an overlay of ConfigMap, Secret and artifact files after the firmware", func() {
+		createArtifact("ov-kernel", "vmlinuz", "kernel", isobootgithubiov1alpha1.BootArtifactPhaseReady)
+		createArtifact("ov-initrd", "initrd.gz", "initrd-data", isobootgithubiov1alpha1.BootArtifactPhaseReady)
+		createArtifact("ov-firmware", "firmware.cpio.gz", "firmware-data", isobootgithubiov1alpha1.BootArtifactPhaseReady)
+		createArtifact("ov-dud", "dd.iso", "driver-disk", isobootgithubiov1alpha1.BootArtifactPhaseReady)
+		cm := createConfigMap("ov-kickstart", map[string]string{"ks.cfg": "text\nreboot\n"})
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ov-ca", Namespace: "default"},
+			Data:       map[string][]byte{"ca.crt": []byte("PEM")},
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, secret) })
+
+		createConfig("ov-bc-netboot", isobootgithubiov1alpha1.BootConfigSpec{
+			Netboot: &isobootgithubiov1alpha1.BootConfigNetbootSpec{KernelRef: "ov-kernel", InitrdRef: "ov-initrd", FirmwareRef: "ov-firmware"},
+			InitrdOverlays: []isobootgithubiov1alpha1.BootConfigInitrdOverlay{
+				{Path: "/ks.cfg", ConfigMapKeyRef: &isobootgithubiov1alpha1.ConfigMapKeyReference{Name: "ov-kickstart", Key: "ks.cfg"}},
+				{Path: "/etc/pki/ca-trust/source/anchors/corp.pem", Mode: new(int32(0o600)),
+					SecretKeyRef: &isobootgithubiov1alpha1.SecretKeyReference{Name: "ov-ca", Key: "ca.crt"}},
+				{Path: "/dd.iso", ArtifactRef: "ov-dud"},
+			},
+		})
+
+		result := doReconcile("ov-bc-netboot")
+		Expect(result.RequeueAfter).To(Equal(overlayResyncInterval))
+		Expect(getConfig("ov-bc-netboot").Status.Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+		entries := readOverlaid(readFile("boot", "ov-bc-netboot", "initrd", "initrd.gz"), "initrd-datafirmware-data")
+		Expect(entries["ks.cfg"].content).To(Equal("text\nreboot\n"))
+		Expect(entries["etc/pki/ca-trust/source/anchors/corp.pem"]).To(Equal(cpioEntry{mode: cpioModeRegular | 0o600, content: "PEM"}))
+		Expect(entries["dd.iso"].content).To(Equal("driver-disk"))
+
+		// The overlay is rebuilt when a ConfigMap changes...
+		cm.Data["ks.cfg"] = "text\npoweroff\n"
+		Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+		doReconcile("ov-bc-netboot")
+		entries = readOverlaid(readFile("boot", "ov-bc-netboot", "initrd", "initrd.gz"), "initrd-datafirmware-data")
+		Expect(entries["ks.cfg"].content).To(Equal("text\npoweroff\n"))
+
+		// ...and dropped with the overlays.
+		bc := getConfig("ov-bc-netboot")
+		bc.Spec.InitrdOverlays = nil
+		Expect(k8sClient.Update(ctx, bc)).To(Succeed())
+		Expect(doReconcile("ov-bc-netboot").RequeueAfter).To(BeZero())
+		Expect(readFile("boot", "ov-bc-netboot", "initrd", "initrd.gz")).To(Equal("initrd-datafirmware-data"))
+		Expect(filepath.Join(dataDir, "boot", "ov-bc-netboot", initrdOverlayFile)).NotTo(BeAnExistingFile())
+	})
+
+	It("serves the extracted initrd with the overlay under overlaid/", func() {
+		createArtifact("ov-netboot", "netboot.tar.gz", "", isobootgithubiov1alpha1.BootArtifactPhaseReady)
+		Expect(writeTestTarGz(filepath.Join(dataDir, "artifacts", "ov-netboot", "netboot.tar.gz"), []archiveMember{
+			{name: "linux", content: "KERNEL-BYTES"},
+			{name: "initrd.gz", content: "INITRD-BYTES"},
+		})).To(Succeed())
+		createConfigMap("ov-preseed", map[string]string{"preseed.cfg": "d-i debian-installer/locale string en_US"})
+
+		createConfig("ov-bc-archive", isobootgithubiov1alpha1.BootConfigSpec{
+			Archive: &isobootgithubiov1alpha1.BootConfigArchiveSpec{ArtifactRef: "ov-netboot", KernelPath: "linux", InitrdPath: "initrd.gz"},
+			InitrdOverlays: []isobootgithubiov1alpha1.BootConfigInitrdOverlay{
+				{Path: "preseed.cfg", ConfigMapKeyRef: &isobootgithubiov1alpha1.ConfigMapKeyReference{Name: "ov-preseed", Key: "preseed.cfg"}},
+			},
+		})
+
+		doReconcile("ov-bc-archive")
+		bc := getConfig("ov-bc-archive")
+		Expect(bc.Status.Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseReady))
+		Expect(bc.ExtractedInitrdPath()).To(Equal("overlaid/initrd"))
+		Expect(readFile("boot", "ov-bc-archive", "initrd")).To(Equal("INITRD-BYTES"))
+		entries := readOverlaid(readFile("boot", "ov-bc-archive", "overlaid", "initrd"), "INITRD-BYTES")
+		Expect(entries["preseed.cfg"].content).To(Equal("d-i debian-installer/locale string en_US"))
+
+		bc.Spec.InitrdOverlays = nil
+		Expect(k8sClient.Update(ctx, bc)).To(Succeed())
+		doReconcile("ov-bc-archive")
+		Expect(filepath.Join(dataDir, "boot", "ov-bc-archive", "overlaid")).NotTo(BeAnExistingFile())
+	})
+
+	It("reports missing sources and waits for overlay artifacts", func() {
+		createArtifact("ov-missing-kernel", "vmlinuz", "kernel", isobootgithubiov1alpha1.BootArtifactPhaseReady)
+		createArtifact("ov-missing-initrd", "initrd.gz", "initrd", isobootgithubiov1alpha1.BootArtifactPhaseReady)
+		createArtifact("ov-downloading", "dd.iso", "", isobootgithubiov1alpha1.BootArtifactPhaseDownloading)
+		netboot := &isobootgithubiov1alpha1.BootConfigNetbootSpec{KernelRef: "ov-missing-kernel", InitrdRef: "ov-missing-initrd"}
+
+		createConfig("ov-bc-no-cm", isobootgithubiov1alpha1.BootConfigSpec{
+			Netboot: netboot,
+			InitrdOverlays: []isobootgithubiov1alpha1.BootConfigInitrdOverlay{
+				{Path: "ks.cfg", ConfigMapKeyRef: &isobootgithubiov1alpha1.ConfigMapKeyReference{Name: "ov-nope", Key: "ks.cfg"}},
+			},
+		})
+		doReconcile("ov-bc-no-cm")
+		status := getConfig("ov-bc-no-cm").Status
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhaseError))
+		Expect(status.Message).To(Equal(`overlay ConfigMap "ov-nope" not found`))
+		Expect(meta.IsStatusConditionFalse(status.Conditions, isobootgithubiov1alpha1.ConditionReferencesResolved)).To(BeTrue())
+
+		createConfig("ov-bc-pending", isobootgithubiov1alpha1.BootConfigSpec{
+			Netboot:        netboot,
+			InitrdOverlays: []isobootgithubiov1alpha1.BootConfigInitrdOverlay{{Path: "dd.iso", ArtifactRef: "ov-downloading"}},
+		})
+		doReconcile("ov-bc-pending")
+		Expect(getConfig("ov-bc-pending").Status.Phase).To(Equal(isobootgithubiov1alpha1.BootConfigPhasePending))
+		Expect(ArtifactRefs(getConfig("ov-bc-pending"))).To(ContainElement("ov-downloading"))
+	})
+
+	It("rejects an overlay with more than one source", func() {
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "ov-bc-two-sources", Namespace: "default"},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				Netboot: &isobootgithubiov1alpha1.BootConfigNetbootSpec{KernelRef: "k", InitrdRef: "i"},
+				InitrdOverlays: []isobootgithubiov1alpha1.BootConfigInitrdOverlay{{
+					Path:            "ks.cfg",
+					ConfigMapKeyRef: &isobootgithubiov1alpha1.ConfigMapKeyReference{Name: "cm", Key: "ks.cfg"},
+					ArtifactRef:     "ks",
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, bc)).To(MatchError(ContainSubstring("exactly one of configMapKeyRef, secretKeyRef or artifactRef")))
+	})
+})
@@ -0,0 +1,229 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+	"github.com/isoboot/isoboot/internal/urlutil"
+)
+
+// maxChecksumFileSize bounds the checksum manifest and signature downloads.
+const maxChecksumFileSize = 4 << 20
+
+// digest is an expected file digest: a hash algorithm and its hex value.
+type digest struct {
+	algorithm string // "sha256" or "sha512"
+	hex       string
+}
+
+// String returns the digest in "<algorithm>:<hex>" form.
+func (d digest) String() string {
+	return d.algorithm + ":" + strings.ToLower(d.hex)
+}
+
+func (d digest) newHash() hash.Hash {
+	if d.algorithm == "sha256" {
+		return sha256.New()
+	}
+	return sha512.New()
+}
+
+// parseDigest parses the "<algorithm>:<hex>" form produced by digest.String.
+func parseDigest(s string) (digest, bool) {
+	algorithm, hexValue, ok := strings.Cut(s, ":")
+	if !ok {
+		return digest{}, false
+	}
+	return digestFromHex(algorithm, hexValue)
+}
+
+// digestFromHex validates hexValue as a digest for algorithm. An empty
+// algorithm is inferred from the digest length.
+func digestFromHex(algorithm, hexValue string) (digest, bool) {
+	if algorithm == "" {
+		switch len(hexValue) {
+		case sha256.Size * 2:
+			algorithm = "sha256"
+		case sha512.Size * 2:
+			algorithm = "sha512"
+		}
+	}
+	var size int
+	switch algorithm {
+	case "sha256":
+		size = sha256.Size
+	case "sha512":
+		size = sha512.Size
+	default:
+		return digest{}, false
+	}
+	if len(hexValue) != size*2 || strings.Trim(hexValue, "0123456789abcdefABCDEF") != "" {
+		return digest{}, false
+	}
+	return digest{algorithm: algorithm, hex: hexValue}, true
+}
+
+// resolveChecksums fetches the checksum manifest and detached signature named
+// by spec.checksums, after ArtifactMirrorPolicy rewrites, verifies the
+// signature against the keyring ConfigMap, and returns the digest listed for
+// the artifact.
+func (r *BootArtifactReconciler) resolveChecksums(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact) (digest, error) {
+	cs := artifact.Spec.Checksums
+
+	keyring, err := r.loadKeyring(ctx, artifact.Namespace, cs.Keyring)
+	if err != nil {
+		return digest{}, err
+	}
+	httpClient, err := r.sourceClient(ctx, artifact)
+	if err != nil {
+		return digest{}, err
+	}
+	rw, err := r.urlRewriter(ctx)
+	if err != nil {
+		return digest{}, err
+	}
+	manifest, err := fetchSmall(ctx, httpClient, rw.rewrite(cs.URL))
+	if err != nil {
+		return digest{}, fmt.Errorf("fetching checksum manifest: %w", err)
+	}
+	signature, err := fetchSmall(ctx, httpClient, rw.rewrite(cs.SignatureURL))
+	if err != nil {
+		return digest{}, fmt.Errorf("fetching checksum signature: %w", err)
+	}
+
+	check := openpgp.CheckDetachedSignature
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN PGP SIGNATURE-----")) {
+		check = openpgp.CheckArmoredDetachedSignature
+	}
+	if _, err := check(keyring, bytes.NewReader(manifest), bytes.NewReader(signature), nil); err != nil {
+		return digest{}, fmt.Errorf("checksum manifest signature: %w", err)
+	}
+
+	if cs.Filename != "" {
+		return lookupChecksum(manifest, cs.Filename, true)
+	}
+	return lookupChecksum(manifest, urlutil.FilenameFromURL(artifact.Spec.URL), false)
+}
+
+// loadKeyring reads the OpenPGP public keys referenced by ref, armored in the
+// ConfigMap's data or binary in its binaryData.
+func (r *BootArtifactReconciler) loadKeyring(ctx context.Context, namespace string, ref isobootgithubiov1alpha1.ConfigMapKeyReference) (openpgp.EntityList, error) {
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, &cm); err != nil {
+		return nil, fmt.Errorf("getting keyring ConfigMap %q: %w", ref.Name, err)
+	}
+	var (
+		keyring openpgp.EntityList
+		err     error
+	)
+	if data, ok := cm.Data[ref.Key]; ok {
+		keyring, err = openpgp.ReadArmoredKeyRing(strings.NewReader(data))
+	} else if data, ok := cm.BinaryData[ref.Key]; ok {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	} else {
+		return nil, fmt.Errorf("keyring ConfigMap %q has no key %q", ref.Name, ref.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading keyring from ConfigMap %q: %w", ref.Name, err)
+	}
+	return keyring, nil
+}
+
+// fetchSmall GETs url and returns its body, refusing anything larger than
+// maxChecksumFileSize.
+func fetchSmall(ctx context.Context, httpClient *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return readLimited(resp.Body, maxChecksumFileSize)
+}
+
+var (
+	// gnuChecksumLine matches "<hex>  <file>" and "<hex> *<file>" (sha256sum).
+	gnuChecksumLine = regexp.MustCompile(`^([0-9a-fA-F]+) [ *](.+)$`)
+	// bsdChecksumLine matches "SHA256 (<file>) = <hex>" (sha256sum --tag).
+	bsdChecksumLine = regexp.MustCompile(`^(SHA256|SHA512) \((.+)\) = ([0-9a-fA-F]+)$`)
+)
+
+// lookupChecksum finds the digest for name in a checksum manifest. With exact
+// set, name must equal the manifest entry (ignoring a leading "./");
+// otherwise it is compared with the entry's last path element. If the
+// manifest lists both SHA-512 and SHA-256 digests for the file, SHA-512 wins.
+func lookupChecksum(manifest []byte, name string, exact bool) (digest, error) {
+	name = strings.TrimPrefix(name, "./")
+	found := map[string]string{} // algorithm -> hex
+	scanner := bufio.NewScanner(bytes.NewReader(manifest))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var algorithm, entry, hexValue string
+		if m := bsdChecksumLine.FindStringSubmatch(line); m != nil {
+			algorithm, entry, hexValue = strings.ToLower(m[1]), m[2], m[3]
+		} else if m := gnuChecksumLine.FindStringSubmatch(line); m != nil {
+			entry, hexValue = m[2], m[1]
+		} else {
+			continue
+		}
+		entry = strings.TrimPrefix(entry, "./")
+		if (exact && entry != name) || (!exact && path.Base(entry) != name) {
+			continue
+		}
+		d, ok := digestFromHex(algorithm, hexValue)
+		if !ok {
+			continue
+		}
+		if prev, dup := found[d.algorithm]; dup && !strings.EqualFold(prev, d.hex) {
+			return digest{}, fmt.Errorf("checksum manifest lists conflicting %s digests for %q; set checksums.filename to the full entry path", d.algorithm, name)
+		}
+		found[d.algorithm] = d.hex
+	}
+	if err := scanner.Err(); err != nil {
+		return digest{}, fmt.Errorf("reading checksum manifest: %w", err)
+	}
+	for _, algorithm := range []string{"sha512", "sha256"} {
+		if hexValue, ok := found[algorithm]; ok {
+			return digest{algorithm: algorithm, hex: hexValue}, nil
+		}
+	}
+	return digest{}, fmt.Errorf("checksum manifest has no entry for %q", name)
+}
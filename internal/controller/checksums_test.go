@@ -0,0 +1,233 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// armoredPublicKey returns e's public key as an ASCII-armored keyring.
+func armoredPublicKey(e *openpgp.Entity) (string, error) {
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := e.Serialize(w); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+var _ = Describe("lookupChecksum", func() {
+	sha256A := sha256Hex([]byte("a"))
+	sha512A := sha512Hex([]byte("a"))
+	sha256B := sha256Hex([]byte("b"))
+
+	DescribeTable("finds the digest for a file",
+		func(manifest, name string, exact bool, want digest) {
+			got, err := lookupChecksum([]byte(manifest), name, exact)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(want))
+		},
+		Entry("GNU text mode", sha256A+"  vmlinuz\n", "vmlinuz", false, digest{"sha256", sha256A}),
+		Entry("GNU binary mode", sha256A+" *vmlinuz\n", "vmlinuz", false, digest{"sha256", sha256A}),
+		Entry("BSD tag", "SHA256 (vmlinuz) = "+sha256A+"\n", "vmlinuz", false, digest{"sha256", sha256A}),
+		Entry("nested path by basename", sha256A+"  ./netboot/amd64/linux\n", "linux", false, digest{"sha256", sha256A}),
+		Entry("nested path exactly", sha256B+"  ./gtk/amd64/linux\n"+sha256A+"  ./netboot/amd64/linux\n", "netboot/amd64/linux", true, digest{"sha256", sha256A}),
+		Entry("prefers sha512", "SHA256 (f.iso) = "+sha256A+"\nSHA512 (f.iso) = "+sha512A+"\n", "f.iso", false, digest{"sha512", sha512A}),
+		Entry("skips comments and other files", "# comment\n"+sha256B+"  other.iso\n"+sha256A+"  f.iso\n", "f.iso", false, digest{"sha256", sha256A}),
+	)
+
+	DescribeTable("rejects manifests without a unique digest",
+		func(manifest, name string, exact bool) {
+			_, err := lookupChecksum([]byte(manifest), name, exact)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("missing entry", sha256A+"  other.iso\n", "f.iso", false),
+		Entry("ambiguous basename", sha256A+"  ./netboot/amd64/linux\n"+sha256B+"  ./gtk/amd64/linux\n", "linux", false),
+		Entry("exact path does not match basename", sha256A+"  ./netboot/amd64/linux\n", "linux", true),
+	)
+})
+
+var _ = Describe("BootArtifact Controller signed checksums", func() {
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootArtifactReconciler
+		signer     *openpgp.Entity
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-checksums-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &BootArtifactReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir}
+		signer, err = openpgp.NewEntity("isoboot test", "", "test@example.com", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() { Expect(os.RemoveAll(dataDir)).To(Succeed()) })
+
+	doReconcile := func(name string) (reconcile.Result, error) {
+		return reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+		})
+	}
+	getStatus := func(name string) isobootgithubiov1alpha1.BootArtifactStatus {
+		var a isobootgithubiov1alpha1.BootArtifact
+		ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)).To(Succeed())
+		return a.Status
+	}
+
+	// makeKeyring stores the public key of e in a ConfigMap.
+	makeKeyring := func(name string, e *openpgp.Entity) func() {
+		key, err := armoredPublicKey(e)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string]string{"keys.asc": key},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, cm)).To(Succeed())
+		return func() { _ = k8sClient.Delete(ctx, cm) }
+	}
+
+	// serveSigned serves content at /images/vmlinuz plus a SHA256SUMS
+	// manifest for it, armor-signed by e.
+	serveSigned := func(content []byte, e *openpgp.Entity) (string, func()) {
+		manifest := []byte(fmt.Sprintf("%s  ./images/initrd.gz\n%s  ./images/vmlinuz\n", sha256Hex([]byte("initrd")), sha256Hex(content)))
+		var sig bytes.Buffer
+		ExpectWithOffset(1, openpgp.ArmoredDetachSign(&sig, e, bytes.NewReader(manifest), nil)).To(Succeed())
+		serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/images/vmlinuz":
+				_, _ = w.Write(content)
+			case "/SHA256SUMS":
+				_, _ = w.Write(manifest)
+			case "/SHA256SUMS.gpg":
+				_, _ = w.Write(sig.Bytes())
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		reconciler.HTTPClient = httpClient
+		return serverURL, cleanup
+	}
+
+	makeArtifact := func(name, serverURL, keyring string) func() {
+		a := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: isobootgithubiov1alpha1.BootArtifactSpec{
+				URL: serverURL + "/images/vmlinuz",
+				Checksums: &isobootgithubiov1alpha1.BootArtifactChecksums{
+					URL:          serverURL + "/SHA256SUMS",
+					SignatureURL: serverURL + "/SHA256SUMS.gpg",
+					Keyring:      isobootgithubiov1alpha1.ConfigMapKeyReference{Name: keyring, Key: "keys.asc"},
+				},
+			},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, a)).To(Succeed())
+		return func() {
+			_ = k8sClient.Delete(ctx, a)
+			// Run the finalizer so the object is actually removed.
+			_, _ = doReconcile(name)
+		}
+	}
+
+	It("downloads and verifies against a signed manifest", func() {
+		content := []byte("signed kernel")
+		serverURL, cleanup := serveSigned(content, signer)
+		defer cleanup()
+		defer makeKeyring("sums-ok-keys", signer)()
+		defer makeArtifact("sums-ok", serverURL, "sums-ok-keys")()
+
+		result, err := doReconcile("sums-ok")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		status := getStatus("sums-ok")
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		Expect(status.ResolvedDigest).To(Equal("sha256:" + sha256Hex(content)))
+		data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", "sums-ok", "vmlinuz"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(content))
+
+		// The resolved digest is reused to verify the file on disk, without
+		// fetching the manifest again.
+		reconciler.HTTPClient = &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, fmt.Errorf("unexpected request")
+		})}
+		_, err = doReconcile("sums-ok")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getStatus("sums-ok").Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+	})
+
+	It("fails when the manifest is signed by an untrusted key", func() {
+		other, err := openpgp.NewEntity("someone else", "", "other@example.com", nil)
+		Expect(err).NotTo(HaveOccurred())
+		serverURL, cleanup := serveSigned([]byte("kernel"), other)
+		defer cleanup()
+		defer makeKeyring("sums-untrusted-keys", signer)()
+		defer makeArtifact("sums-untrusted", serverURL, "sums-untrusted-keys")()
+
+		result, err := doReconcile("sums-untrusted")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).NotTo(BeZero())
+
+		status := getStatus("sums-untrusted")
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+		Expect(status.Message).To(ContainSubstring("signature"))
+		Expect(status.ResolvedDigest).To(BeEmpty())
+		_, err = os.Stat(filepath.Join(dataDir, "artifacts", "sums-untrusted", "vmlinuz"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("fails when the keyring ConfigMap is missing", func() {
+		serverURL, cleanup := serveSigned([]byte("kernel"), signer)
+		defer cleanup()
+		defer makeArtifact("sums-nokeys", serverURL, "no-such-keyring")()
+
+		_, err := doReconcile("sums-nokeys")
+		Expect(err).NotTo(HaveOccurred())
+		status := getStatus("sums-nokeys")
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+		Expect(status.Message).To(ContainSubstring("no-such-keyring"))
+	})
+})
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,129 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// Condition reasons that are not a phase or failure reason of their own.
+const (
+	reasonResolved        = "Resolved"
+	reasonAssembled       = "Assembled"
+	reasonArtifactMissing = "ArtifactNotFound"
+	reasonArtifactPending = "ArtifactNotReady"
+	reasonRefMissing      = "ReferenceNotFound"
+	reasonInvalidPath     = "InvalidPath"
+	reasonAssemblyFailed  = "AssemblyFailed"
+	reasonExtractFailed   = "ExtractionFailed"
+	reasonDetectionFailed = "DetectionFailed"
+	reasonValid           = "Valid"
+	reasonDuplicateMAC    = "DuplicateMAC"
+	reasonInvalidTemplate = "InvalidTemplate"
+)
+
+// setCondition sets a condition observed at generation and reports whether
+// anything but lastTransitionTime changed.
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, status bool, reason, message string) bool {
+	s := metav1.ConditionFalse
+	if status {
+		s = metav1.ConditionTrue
+	}
+	return meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             s,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setArtifactConditions derives Ready, Progressing and Degraded from the
+// artifact's phase. reason explains the phase; it is the failure reason
+// while the phase is Error.
+func setArtifactConditions(artifact *isobootgithubiov1alpha1.BootArtifact, reason string) {
+	phase := artifact.Status.Phase
+	if phase == "" {
+		phase = isobootgithubiov1alpha1.BootArtifactPhasePending
+	}
+	if reason == "" {
+		reason = string(phase)
+	}
+	conditions, gen, msg := &artifact.Status.Conditions, artifact.Generation, artifact.Status.Message
+	setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionReady,
+		phase == isobootgithubiov1alpha1.BootArtifactPhaseReady, reason, msg)
+	setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionProgressing,
+		phase == isobootgithubiov1alpha1.BootArtifactPhasePending ||
+			phase == isobootgithubiov1alpha1.BootArtifactPhaseQueued ||
+			phase == isobootgithubiov1alpha1.BootArtifactPhaseDownloading, reason, msg)
+	setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionDegraded,
+		phase == isobootgithubiov1alpha1.BootArtifactPhaseError, reason, msg)
+}
+
+// setBootConfigConditions derives Ready, Progressing, Degraded and
+// ReferencesResolved from the boot config's phase and reason, and reports
+// whether any of them changed.
+func setBootConfigConditions(bc *isobootgithubiov1alpha1.BootConfig, reason string) bool {
+	phase := bc.Status.Phase
+	conditions, gen, msg := &bc.Status.Conditions, bc.Generation, bc.Status.Message
+	changed := setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionReady,
+		phase == isobootgithubiov1alpha1.BootConfigPhaseReady, reason, msg)
+	changed = setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionProgressing,
+		phase == isobootgithubiov1alpha1.BootConfigPhasePending, reason, msg) || changed
+	changed = setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionDegraded,
+		phase == isobootgithubiov1alpha1.BootConfigPhaseError, reason, msg) || changed
+	switch reason {
+	case reasonArtifactMissing, reasonRefMissing:
+		changed = setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionReferencesResolved,
+			false, reason, msg) || changed
+	case reasonInvalidPath:
+		// Rejected before the references were looked up.
+	default:
+		changed = setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionReferencesResolved,
+			true, reasonResolved, "") || changed
+	}
+	return changed
+}
+
+// setProvisionConditions derives Ready, Progressing and Degraded from the
+// provision's phase, and ReferencesResolved from unresolved (empty when
+// every reference exists), and reports whether any of them changed.
+func setProvisionConditions(prov *isobootgithubiov1alpha1.Provision, unresolvedReason, unresolved string) bool {
+	phase := prov.Status.Phase
+	reason := string(phase)
+	conditions, gen, msg := &prov.Status.Conditions, prov.Generation, prov.Status.Message
+	changed := setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionReady,
+		phase == isobootgithubiov1alpha1.ProvisionPhaseComplete, reason, msg)
+	changed = setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionProgressing,
+		phase == isobootgithubiov1alpha1.ProvisionPhasePending ||
+			phase == isobootgithubiov1alpha1.ProvisionPhaseWaitingForBootSource ||
+			phase == isobootgithubiov1alpha1.ProvisionPhaseInProgress, reason, msg) || changed
+	changed = setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionDegraded,
+		phase == isobootgithubiov1alpha1.ProvisionPhaseFailed ||
+			phase == isobootgithubiov1alpha1.ProvisionPhaseConfigError, reason, msg) || changed
+	if unresolved != "" {
+		changed = setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionReferencesResolved,
+			false, unresolvedReason, unresolved) || changed
+	} else {
+		changed = setCondition(conditions, gen, isobootgithubiov1alpha1.ConditionReferencesResolved,
+			true, reasonResolved, "") || changed
+	}
+	return changed
+}
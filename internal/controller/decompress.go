@@ -0,0 +1,137 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// servedDigest returns the digest of the file served for the artifact: want
+// itself, or for spec.decompress the digest recorded in
+// status.decompressedDigest. It returns false when that is not known yet.
+func servedDigest(artifact *isobootgithubiov1alpha1.BootArtifact, want digest) (digest, bool) {
+	if artifact.Spec.Decompress == "" {
+		return want, true
+	}
+	return parseDigest(artifact.Status.DecompressedDigest)
+}
+
+// commitDownload stores the verified download at tmpPath as filePath and
+// returns the digest of the stored file. With spec.decompress the download is
+// decompressed into filePath instead, via a temporary file so the old file is
+// replaced atomically.
+func (r *BootArtifactReconciler) commitDownload(artifact *isobootgithubiov1alpha1.BootArtifact, tmpPath, filePath string, want digest) (digest, error) {
+	if artifact.Spec.Decompress == "" {
+		return want, r.commitBlob(tmpPath, filePath, want)
+	}
+
+	// ensureSpace only covered the compressed download, so the output is
+	// capped to keep a decompression bomb from filling the data directory.
+	available, err := availableSpace(r.DataDir)
+	if err != nil {
+		return digest{}, fmt.Errorf("checking free space: %w", err)
+	}
+	maxSize := max(available-r.MinFreeSpace, 0)
+	outPath := filePath + ".decompress.tmp"
+	out, err := decompressFile(tmpPath, outPath, artifact.Spec.Decompress, maxSize)
+	if errors.Is(err, errDecompressedTooLarge) {
+		err = &insufficientSpaceError{need: maxSize + 1 + r.MinFreeSpace, available: available}
+	}
+	if err != nil {
+		_ = os.Remove(outPath)
+		return digest{}, fmt.Errorf("decompressing %s: %w", artifact.Spec.Decompress, err)
+	}
+	if err := r.commitBlob(outPath, filePath, out); err != nil {
+		_ = os.Remove(outPath)
+		return digest{}, err
+	}
+	_ = os.Remove(tmpPath)
+	return out, nil
+}
+
+// errDecompressedTooLarge is returned by decompressFile when the output
+// exceeds its maximum size.
+var errDecompressedTooLarge = errors.New("decompressed output too large")
+
+// decompressFile writes the decompressed contents of srcPath to dstPath and
+// returns the SHA-256 digest of the output, which may not exceed maxSize
+// bytes.
+func decompressFile(srcPath, dstPath string, format isobootgithubiov1alpha1.BootArtifactCompression, maxSize int64) (digest, error) {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return digest{}, err
+	}
+	defer func() { _ = src.Close() }()
+
+	var r io.Reader
+	switch format {
+	case isobootgithubiov1alpha1.BootArtifactCompressionGzip:
+		zr, err := gzip.NewReader(src)
+		if err != nil {
+			return digest{}, err
+		}
+		defer func() { _ = zr.Close() }()
+		r = zr
+	case isobootgithubiov1alpha1.BootArtifactCompressionXZ:
+		if r, err = xz.NewReader(src); err != nil {
+			return digest{}, err
+		}
+	case isobootgithubiov1alpha1.BootArtifactCompressionZstd:
+		zr, err := zstd.NewReader(src, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return digest{}, err
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return digest{}, fmt.Errorf("unsupported format %q", format)
+	}
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return digest{}, err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(dst, h), io.LimitReader(r, maxSize+1))
+	if err != nil {
+		_ = dst.Close()
+		return digest{}, err
+	}
+	if n > maxSize {
+		_ = dst.Close()
+		return digest{}, errDecompressedTooLarge
+	}
+	if err := dst.Sync(); err != nil {
+		_ = dst.Close()
+		return digest{}, err
+	}
+	if err := dst.Close(); err != nil {
+		return digest{}, err
+	}
+	return digest{algorithm: "sha256", hex: hex.EncodeToString(h.Sum(nil))}, nil
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/ulikunitz/xz"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// compress returns data compressed in format.
+func compress(format isobootgithubiov1alpha1.BootArtifactCompression, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch format {
+	case isobootgithubiov1alpha1.BootArtifactCompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case isobootgithubiov1alpha1.BootArtifactCompressionXZ:
+		w, err = xz.NewWriter(&buf)
+	case isobootgithubiov1alpha1.BootArtifactCompressionZstd:
+		w, err = zstd.NewWriter(&buf)
+	}
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	_, err = w.Write(data)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	ExpectWithOffset(1, w.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+var _ = Describe("BootArtifact Controller decompression", func() {
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootArtifactReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-decompress-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &BootArtifactReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir}
+	})
+	AfterEach(func() { Expect(os.RemoveAll(dataDir)).To(Succeed()) })
+
+	doReconcile := func(name string) (reconcile.Result, error) {
+		return reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+		})
+	}
+	getStatus := func(name string) isobootgithubiov1alpha1.BootArtifactStatus {
+		var a isobootgithubiov1alpha1.BootArtifact
+		ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)).To(Succeed())
+		return a.Status
+	}
+	makeArtifact := func(name string, spec isobootgithubiov1alpha1.BootArtifactSpec) func() {
+		a := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       spec,
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, a)).To(Succeed())
+		return func() {
+			_ = k8sClient.Delete(ctx, a)
+			// Run the finalizer so the object is actually removed.
+			_, _ = doReconcile(name)
+		}
+	}
+
+	DescribeTable("serves the decompressed file",
+		func(name string, format isobootgithubiov1alpha1.BootArtifactCompression, filename string) {
+			content := []byte("decompressed kernel " + name)
+			compressed := compress(format, content)
+			var requests atomic.Int32
+			serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				_, _ = w.Write(compressed)
+			})
+			defer cleanup()
+			reconciler.HTTPClient = httpClient
+
+			defer makeArtifact(name, isobootgithubiov1alpha1.BootArtifactSpec{
+				URL:        serverURL + "/" + filename,
+				SHA256:     new(sha256Hex(compressed)),
+				Decompress: format,
+			})()
+
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			status := getStatus(name)
+			Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			Expect(status.DecompressedDigest).To(Equal("sha256:" + sha256Hex(content)))
+
+			dir := filepath.Join(dataDir, "artifacts", name)
+			data, err := os.ReadFile(filepath.Join(dir, "vmlinuz"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(content))
+			Expect(filepath.Join(dir, filename)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(dir, "vmlinuz.tmp")).NotTo(BeAnExistingFile())
+
+			// The served file is verified against the recorded digest.
+			_, err = doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+			Expect(requests.Load()).To(Equal(int32(1)))
+		},
+		Entry("gzip", "decompress-gzip", isobootgithubiov1alpha1.BootArtifactCompressionGzip, "vmlinuz.gz"),
+		Entry("xz", "decompress-xz", isobootgithubiov1alpha1.BootArtifactCompressionXZ, "vmlinuz.xz"),
+		Entry("zstd", "decompress-zstd", isobootgithubiov1alpha1.BootArtifactCompressionZstd, "vmlinuz.zst"),
+	)
+
+	It("re-downloads when the decompressed file is corrupted", func() {
+		content := []byte("decompressed initrd")
+		compressed := compress(isobootgithubiov1alpha1.BootArtifactCompressionGzip, content)
+		var requests atomic.Int32
+		serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			_, _ = w.Write(compressed)
+		})
+		defer cleanup()
+		reconciler.HTTPClient = httpClient
+
+		name := "decompress-corrupt"
+		defer makeArtifact(name, isobootgithubiov1alpha1.BootArtifactSpec{
+			URL:        serverURL + "/initrd.gz",
+			SHA256:     new(sha256Hex(compressed)),
+			Decompress: isobootgithubiov1alpha1.BootArtifactCompressionGzip,
+		})()
+
+		_, err := doReconcile(name)
+		Expect(err).NotTo(HaveOccurred())
+		filePath := filepath.Join(dataDir, "artifacts", name, "initrd")
+		Expect(os.Remove(filePath)).To(Succeed())
+		Expect(os.WriteFile(filePath, []byte("bit rot"), 0o644)).To(Succeed())
+
+		_, err = doReconcile(name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests.Load()).To(Equal(int32(2)))
+		Expect(getStatus(name).Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		data, err := os.ReadFile(filePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(content))
+	})
+
+	It("keeps the decompressed digest across edits that do not change the file", func() {
+		content := []byte("decompressed kernel")
+		compressed := compress(isobootgithubiov1alpha1.BootArtifactCompressionXZ, content)
+		var requests atomic.Int32
+		serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			_, _ = w.Write(compressed)
+		})
+		defer cleanup()
+		reconciler.HTTPClient = httpClient
+
+		name := "decompress-priority"
+		defer makeArtifact(name, isobootgithubiov1alpha1.BootArtifactSpec{
+			URL:        serverURL + "/vmlinuz.xz",
+			SHA256:     new(sha256Hex(compressed)),
+			Decompress: isobootgithubiov1alpha1.BootArtifactCompressionXZ,
+		})()
+		_, err := doReconcile(name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests.Load()).To(Equal(int32(1)))
+
+		var a isobootgithubiov1alpha1.BootArtifact
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)).To(Succeed())
+		a.Spec.Priority = 10
+		Expect(k8sClient.Update(ctx, &a)).To(Succeed())
+
+		_, err = doReconcile(name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests.Load()).To(Equal(int32(1)))
+		status := getStatus(name)
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		Expect(status.ObservedGeneration).To(Equal(a.Generation))
+		Expect(status.DecompressedDigest).To(Equal("sha256:" + sha256Hex(content)))
+	})
+
+	It("caps the decompressed file at the free space above MinFreeSpace", func() {
+		content := bytes.Repeat([]byte{0}, 1<<20)
+		tmpPath := filepath.Join(dataDir, "vmlinuz.tmp")
+		Expect(os.WriteFile(tmpPath, compress(isobootgithubiov1alpha1.BootArtifactCompressionGzip, content), 0o644)).To(Succeed())
+		filePath := filepath.Join(dataDir, "vmlinuz")
+
+		_, err := decompressFile(tmpPath, filePath+".out", isobootgithubiov1alpha1.BootArtifactCompressionGzip, 1<<20)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = decompressFile(tmpPath, filePath+".out", isobootgithubiov1alpha1.BootArtifactCompressionGzip, 1<<20-1)
+		Expect(err).To(MatchError(errDecompressedTooLarge))
+
+		reconciler.MinFreeSpace = 1 << 62
+		artifact := &isobootgithubiov1alpha1.BootArtifact{
+			Spec: isobootgithubiov1alpha1.BootArtifactSpec{Decompress: isobootgithubiov1alpha1.BootArtifactCompressionGzip},
+		}
+		_, err = reconciler.commitDownload(artifact, tmpPath, filePath, digest{})
+		_, ok := errors.AsType[*insufficientSpaceError](err)
+		Expect(ok).To(BeTrue(), "%v", err)
+		Expect(filePath + ".decompress.tmp").NotTo(BeAnExistingFile())
+		Expect(filePath).NotTo(BeAnExistingFile())
+	})
+
+	It("fails when the verified download is not valid compressed data", func() {
+		content := []byte("not actually xz")
+		serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(content)
+		})
+		defer cleanup()
+		reconciler.HTTPClient = httpClient
+
+		name := "decompress-invalid"
+		defer makeArtifact(name, isobootgithubiov1alpha1.BootArtifactSpec{
+			URL:        serverURL + "/vmlinuz.xz",
+			SHA256:     new(sha256Hex(content)),
+			Decompress: isobootgithubiov1alpha1.BootArtifactCompressionXZ,
+		})()
+
+		result, err := doReconcile(name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).NotTo(BeZero())
+		status := getStatus(name)
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+		Expect(status.Message).To(ContainSubstring("decompressing xz"))
+		Expect(filepath.Join(dataDir, "artifacts", name, "vmlinuz")).NotTo(BeAnExistingFile())
+	})
+})
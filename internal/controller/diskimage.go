@@ -0,0 +1,110 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	diskfs "github.com/diskfs/go-diskfs"
+	"github.com/diskfs/go-diskfs/disk"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// reconcileDiskImage handles disk image mode: extract the kernel, initrd and
+// any extra files from a partition of a raw or GPT disk image into the boot
+// directory.
+func (r *BootConfigReconciler) reconcileDiskImage(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig) (ctrl.Result, error) {
+	di := bc.Spec.DiskImage
+	return r.reconcileExtraction(ctx, bc, extraction{
+		kind: "disk image", artifactRef: di.ArtifactRef,
+		kernelPath: di.KernelPath, initrdPath: di.InitrdPath, extraFiles: di.ExtraFiles,
+		extract: func(imagePath, bootDir string, files []isoFile, current bool) error {
+			return extractFromDiskImage(imagePath, di, bootDir, files, current)
+		},
+	})
+}
+
+// extractFromDiskImage writes files from the partition of the disk image at
+// imagePath selected by di into outputDir. When current is set and every
+// output exists, the image is not opened at all.
+func extractFromDiskImage(imagePath string, di *isobootgithubiov1alpha1.BootConfigDiskImageSpec, outputDir string, files []isoFile, current bool) error {
+	if current && !slices.ContainsFunc(files, func(f isoFile) bool {
+		return !extracted(filepath.Join(outputDir, filepath.FromSlash(f.name)))
+	}) {
+		return nil // already extracted and current
+	}
+
+	d, err := diskfs.Open(imagePath, diskfs.WithOpenMode(diskfs.ReadOnly))
+	if err != nil {
+		return fmt.Errorf("opening disk image %q: %w", imagePath, err)
+	}
+	defer func() { _ = d.Close() }()
+
+	index := 0
+	if di.Partition != nil {
+		index = int(*di.Partition)
+	} else if index, err = findPartition(d, di.PartitionLabel); err != nil {
+		return err
+	}
+	fsys, err := d.GetFilesystem(index)
+	if err != nil {
+		return fmt.Errorf("reading filesystem of partition %d: %w", index, err)
+	}
+
+	for _, f := range files {
+		dst := filepath.Join(outputDir, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return fmt.Errorf("creating directory for %q: %w", f.name, err)
+		}
+		if err := extractFile(fsys, f.src, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findPartition returns the index of the partition of d whose GPT partition
+// name is label or, failing that, whose filesystem label is. An image
+// without a partition table is matched as partition 0.
+func findPartition(d *disk.Disk, label string) (int, error) {
+	if d.Table == nil {
+		if fsys, err := d.GetFilesystem(0); err == nil && strings.TrimSpace(fsys.Label()) == label {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("no filesystem labelled %q in the disk image", label)
+	}
+	parts := d.Table.GetPartitions()
+	for _, p := range parts {
+		if p.Label() == label {
+			return p.GetIndex(), nil
+		}
+	}
+	for _, p := range parts {
+		// Partitions with no filesystem go-diskfs can read are skipped.
+		if fsys, err := d.GetFilesystem(p.GetIndex()); err == nil && strings.TrimSpace(fsys.Label()) == label {
+			return p.GetIndex(), nil
+		}
+	}
+	return 0, fmt.Errorf("no partition labelled %q in the disk image", label)
+}
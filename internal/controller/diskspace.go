@@ -0,0 +1,249 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/djherbis/times"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// insufficientSpaceError reports that a download does not fit in the data
+// directory. It applies to every source, so the mirrors are not tried.
+type insufficientSpaceError struct {
+	need      int64
+	available int64
+}
+
+func (e *insufficientSpaceError) Error() string {
+	return fmt.Sprintf("insufficient disk space: need %d bytes, %d available", e.need, e.available)
+}
+
+// availableSpace returns the bytes available to unprivileged users on the
+// filesystem holding dir.
+func availableSpace(dir string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil //nolint:unconvert // field types differ between platforms
+}
+
+// ensureSpace checks that need more bytes fit in the data directory while
+// leaving MinFreeSpace free. When they do not and EvictUnreferenced is set,
+// files of artifacts no BootConfig uses are evicted first.
+func (r *BootArtifactReconciler) ensureSpace(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, need int64) error {
+	available, err := availableSpace(r.DataDir)
+	if err != nil {
+		return fmt.Errorf("checking free space: %w", err)
+	}
+	if available-need >= r.MinFreeSpace {
+		return nil
+	}
+	if r.EvictUnreferenced {
+		if err := r.evictUnreferenced(ctx, artifact, need+r.MinFreeSpace-available); err != nil {
+			return fmt.Errorf("evicting unreferenced artifacts: %w", err)
+		}
+		if available, err = availableSpace(r.DataDir); err != nil {
+			return fmt.Errorf("checking free space: %w", err)
+		}
+		if available-need >= r.MinFreeSpace {
+			return nil
+		}
+	}
+	return &insufficientSpaceError{need: need + r.MinFreeSpace, available: available}
+}
+
+// setDiskPressure records a download held back by insufficientSpaceError as
+// the reason this artifact failed. The data directory's own state is
+// reported by DataDirCheck and DataDirCollector.
+func (r *BootArtifactReconciler) setDiskPressure(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, err *insufficientSpaceError) (ctrl.Result, error) {
+	meta.SetStatusCondition(&artifact.Status.Conditions, metav1.Condition{
+		Type:               isobootgithubiov1alpha1.BootArtifactConditionDiskPressure,
+		Status:             metav1.ConditionTrue,
+		Reason:             "InsufficientSpace",
+		Message:            err.Error(),
+		ObservedGeneration: artifact.Generation,
+	})
+	if r.Recorder != nil {
+		r.Recorder.Eventf(artifact, nil, "Warning", "DiskPressure", "Download", "%s", err.Error())
+	}
+	return r.setFailure(ctx, artifact, failureReasonDiskPressure, err.Error())
+}
+
+// evictUnreferenced frees at least want bytes, if it can, by removing the
+// files of Ready artifacts that no BootConfig references, least recently
+// accessed first. Evicted artifacts move to the Evicted phase and are
+// downloaded again once a BootConfig references them.
+func (r *BootArtifactReconciler) evictUnreferenced(ctx context.Context, keep *isobootgithubiov1alpha1.BootArtifact, want int64) error {
+	log := logf.FromContext(ctx)
+
+	var artifacts isobootgithubiov1alpha1.BootArtifactList
+	if err := r.List(ctx, &artifacts); err != nil {
+		return err
+	}
+	var configs isobootgithubiov1alpha1.BootConfigList
+	if err := r.List(ctx, &configs); err != nil {
+		return err
+	}
+	referenced := map[client.ObjectKey]bool{}
+	for i := range configs.Items {
+		for _, ref := range ArtifactRefs(&configs.Items[i]) {
+			referenced[client.ObjectKey{Namespace: configs.Items[i].Namespace, Name: ref}] = true
+		}
+	}
+
+	type candidate struct {
+		artifact   *isobootgithubiov1alpha1.BootArtifact
+		lastAccess time.Time
+	}
+	var candidates []candidate
+	for i := range artifacts.Items {
+		a := &artifacts.Items[i]
+		key := client.ObjectKeyFromObject(a)
+		if key == client.ObjectKeyFromObject(keep) || referenced[key] ||
+			a.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady || !a.DeletionTimestamp.IsZero() {
+			continue
+		}
+		info, err := os.Stat(r.filePath(a))
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{artifact: a, lastAccess: lastAccess(info)})
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int { return a.lastAccess.Compare(b.lastAccess) })
+
+	before, err := availableSpace(r.DataDir)
+	if err != nil {
+		return err
+	}
+	for _, c := range candidates {
+		dir := filepath.Dir(r.filePath(c.artifact))
+		if err := os.RemoveAll(dir); err != nil {
+			log.Error(err, "Failed to evict artifact", "artifact", client.ObjectKeyFromObject(c.artifact))
+			continue
+		}
+		r.pruneBlobs(ctx)
+		log.Info("Evicted unreferenced artifact to free disk space", "artifact", client.ObjectKeyFromObject(c.artifact), "path", dir)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(c.artifact, nil, "Normal", "Evicted", "Evict",
+				"Removed to free disk space for %s/%s", keep.Namespace, keep.Name)
+		}
+		c.artifact.Status.Phase = isobootgithubiov1alpha1.BootArtifactPhaseEvicted
+		c.artifact.Status.Message = "Evicted to free disk space; downloaded again when a BootConfig references it"
+		if err := r.updateStatus(ctx, c.artifact); err != nil {
+			log.Error(err, "Failed to mark artifact evicted", "artifact", client.ObjectKeyFromObject(c.artifact))
+		}
+
+		// Blobs shared with other artifacts stay on disk, so measure
+		// rather than sum file sizes.
+		available, err := availableSpace(r.DataDir)
+		if err != nil {
+			return err
+		}
+		if available-before >= want {
+			return nil
+		}
+	}
+	return nil
+}
+
+// lastAccess returns the file's access time. With relatime mounts this is
+// updated at most once a day, which is enough to order evictions.
+func lastAccess(info os.FileInfo) time.Time {
+	return times.Get(info).AccessTime()
+}
+
+// isReferenced reports whether a BootConfig in the artifact's namespace
+// references it.
+func (r *BootArtifactReconciler) isReferenced(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact) (bool, error) {
+	var configs isobootgithubiov1alpha1.BootConfigList
+	if err := r.List(ctx, &configs, client.InNamespace(artifact.Namespace)); err != nil {
+		return false, err
+	}
+	for i := range configs.Items {
+		if slices.Contains(ArtifactRefs(&configs.Items[i]), artifact.Name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// findEvictedArtifactsForBootConfig wakes evicted artifacts that a
+// BootConfig references, so they are downloaded again.
+func (r *BootArtifactReconciler) findEvictedArtifactsForBootConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	bc, ok := obj.(*isobootgithubiov1alpha1.BootConfig)
+	if !ok {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, ref := range ArtifactRefs(bc) {
+		key := client.ObjectKey{Namespace: bc.Namespace, Name: ref}
+		var artifact isobootgithubiov1alpha1.BootArtifact
+		if err := r.Get(ctx, key, &artifact); err != nil ||
+			artifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseEvicted {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: key})
+	}
+	return requests
+}
+
+// DataDirCheck returns a readiness check that fails while dataDir is missing,
+// not a directory, not writable, its filesystem cannot be queried, or it has
+// less than minFreeSpace bytes available.
+func DataDirCheck(dataDir string, minFreeSpace int64) healthz.Checker {
+	return func(_ *http.Request) error {
+		info, err := os.Stat(dataDir)
+		if err != nil {
+			return fmt.Errorf("data directory: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("data directory %s is not a directory", dataDir)
+		}
+		f, err := os.CreateTemp(dataDir, ".readyz-*")
+		if err != nil {
+			return fmt.Errorf("data directory is not writable: %w", err)
+		}
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		available, err := availableSpace(dataDir)
+		if err != nil {
+			return fmt.Errorf("data directory filesystem: %w", err)
+		}
+		if available < minFreeSpace {
+			return fmt.Errorf("data directory under disk pressure: %d bytes available, %d required", available, minFreeSpace)
+		}
+		return nil
+	}
+}
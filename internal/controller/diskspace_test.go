@@ -0,0 +1,183 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+var _ = Describe("DataDirCheck", func() {
+	It("passes for a writable directory", func() {
+		Expect(DataDirCheck(GinkgoT().TempDir(), 0)(nil)).To(Succeed())
+	})
+
+	It("fails for a missing directory", func() {
+		Expect(DataDirCheck(filepath.Join(GinkgoT().TempDir(), "missing"), 0)(nil)).To(MatchError(ContainSubstring("data directory")))
+	})
+
+	It("fails for a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "file")
+		Expect(os.WriteFile(path, nil, 0o644)).To(Succeed())
+		Expect(DataDirCheck(path, 0)(nil)).To(MatchError(ContainSubstring("not a directory")))
+	})
+
+	It("fails below the minimum free space", func() {
+		Expect(DataDirCheck(GinkgoT().TempDir(), 1<<62)(nil)).To(MatchError(ContainSubstring("disk pressure")))
+	})
+})
+
+var _ = Describe("BootArtifact Controller disk space", func() {
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootArtifactReconciler
+		serverURL  string
+		cleanup    func()
+		requests   atomic.Int32
+	)
+
+	// contentFor returns 1 MiB of content unique to path, so every artifact
+	// is its own blob and evicting it frees space.
+	contentFor := func(path string) []byte {
+		return bytes.Repeat([]byte(path), (1<<20)/len(path))
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-diskspace-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		requests.Store(0)
+		reconciler = &BootArtifactReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir}
+		serverURL, reconciler.HTTPClient, cleanup = withTestServer(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			_, _ = w.Write(contentFor(r.URL.Path))
+		})
+	})
+	AfterEach(func() {
+		cleanup()
+		Expect(os.RemoveAll(dataDir)).To(Succeed())
+	})
+
+	doReconcile := func(name string) (reconcile.Result, error) {
+		return reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+		})
+	}
+	getArtifact := func(name string) *isobootgithubiov1alpha1.BootArtifact {
+		var a isobootgithubiov1alpha1.BootArtifact
+		ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)).To(Succeed())
+		return &a
+	}
+	makeArtifact := func(name, path string) func() {
+		a := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       isobootgithubiov1alpha1.BootArtifactSpec{URL: serverURL + path, SHA256: new(sha256Hex(contentFor(path)))},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, a)).To(Succeed())
+		return func() {
+			_ = k8sClient.Delete(ctx, a)
+			// Run the finalizer so the object is actually removed.
+			_, _ = doReconcile(name)
+		}
+	}
+	makeBootConfig := func(name, kernelRef string) func() {
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				Netboot: &isobootgithubiov1alpha1.BootConfigNetbootSpec{KernelRef: kernelRef, InitrdRef: "unused-initrd"},
+			},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, bc)).To(Succeed())
+		return func() { _ = k8sClient.Delete(ctx, bc) }
+	}
+
+	It("refuses a download that does not fit and records DiskPressure", func() {
+		reconciler.MinFreeSpace = 1 << 62
+		defer makeArtifact("space-short", "/vmlinuz")()
+
+		result, err := doReconcile("space-short")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).NotTo(BeZero())
+
+		a := getArtifact("space-short")
+		Expect(a.Status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+		Expect(a.Status.Message).To(ContainSubstring("insufficient disk space"))
+		Expect(meta.IsStatusConditionTrue(a.Status.Conditions, isobootgithubiov1alpha1.BootArtifactConditionDiskPressure)).To(BeTrue())
+		Expect(filepath.Join(dataDir, "artifacts", "space-short", "vmlinuz")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(dataDir, "artifacts", "space-short", "vmlinuz.tmp")).NotTo(BeAnExistingFile())
+
+		// Once there is room the download goes ahead and the condition clears.
+		reconciler.MinFreeSpace = 0
+		_, err = doReconcile("space-short")
+		Expect(err).NotTo(HaveOccurred())
+		a = getArtifact("space-short")
+		Expect(a.Status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		Expect(meta.FindStatusCondition(a.Status.Conditions, isobootgithubiov1alpha1.BootArtifactConditionDiskPressure)).To(BeNil())
+	})
+
+	It("evicts unreferenced artifacts and downloads them again once referenced", func() {
+		defer makeArtifact("evict-unused", "/unused")()
+		defer makeArtifact("evict-used", "/used")()
+		defer makeBootConfig("evict-config", "evict-used")()
+		for _, name := range []string{"evict-unused", "evict-used"} {
+			_, err := doReconcile(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getArtifact(name).Status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		}
+		Expect(requests.Load()).To(Equal(int32(2)))
+
+		unusedPath := filepath.Join(dataDir, "artifacts", "evict-unused", "unused")
+		Expect(reconciler.evictUnreferenced(ctx, getArtifact("evict-used"), 1)).To(Succeed())
+		Expect(unusedPath).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(dataDir, "artifacts", "evict-used", "used")).To(BeAnExistingFile())
+		Expect(getArtifact("evict-unused").Status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseEvicted))
+		Expect(getArtifact("evict-used").Status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+
+		// Not referenced: the evicted artifact stays off disk.
+		_, err := doReconcile("evict-unused")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unusedPath).NotTo(BeAnExistingFile())
+		Expect(requests.Load()).To(Equal(int32(2)))
+
+		// A BootConfig referencing it wakes it up and it is downloaded again.
+		defer makeBootConfig("evict-config-2", "evict-unused")()
+		var bc isobootgithubiov1alpha1.BootConfig
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "evict-config-2", Namespace: "default"}, &bc)).To(Succeed())
+		Expect(reconciler.findEvictedArtifactsForBootConfig(ctx, &bc)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "evict-unused", Namespace: "default"}},
+		))
+		_, err = doReconcile("evict-unused")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getArtifact("evict-unused").Status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		Expect(unusedPath).To(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,201 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// Reasons of the UpstreamChanged condition.
+const (
+	reasonUpstreamChanged   = "UpstreamChanged"
+	reasonUpstreamUnchanged = "Unchanged"
+)
+
+// driftCheckEnabled reports whether the source of the artifact's file can be
+// checked for drift: spec.driftCheckInterval is set and the file was
+// downloaded from a source other than an OCI registry.
+func driftCheckEnabled(artifact *isobootgithubiov1alpha1.BootArtifact) bool {
+	return artifact.Spec.DriftCheckInterval != nil && artifact.Spec.DriftCheckInterval.Duration > 0 &&
+		artifact.Status.Provenance != nil && artifact.Status.Provenance.CompletionTime != nil &&
+		artifact.Status.SourceURL != "" && !strings.HasPrefix(artifact.Status.SourceURL, ociScheme)
+}
+
+// driftCheckDue reports whether a drift check is enabled and due.
+func driftCheckDue(artifact *isobootgithubiov1alpha1.BootArtifact) bool {
+	return driftCheckEnabled(artifact) && nextDriftCheck(artifact) <= 0
+}
+
+// nextDriftCheck returns the time until the next drift check is due, or 0
+// when drift checks are disabled. The first check is due one interval after
+// the download.
+func nextDriftCheck(artifact *isobootgithubiov1alpha1.BootArtifact) time.Duration {
+	if !driftCheckEnabled(artifact) {
+		return 0
+	}
+	last := artifact.Status.Provenance.CompletionTime
+	if artifact.Status.LastDriftCheck != nil {
+		last = artifact.Status.LastDriftCheck
+	}
+	return time.Until(last.Add(artifact.Spec.DriftCheckInterval.Duration))
+}
+
+// nextCheck returns the requeue delay for a Ready artifact: the sooner of
+// the next periodic verification and the next drift check, or 0 when
+// neither is enabled.
+func (r *BootArtifactReconciler) nextCheck(artifact *isobootgithubiov1alpha1.BootArtifact) time.Duration {
+	next := r.nextVerification(artifact)
+	if !driftCheckEnabled(artifact) {
+		return next
+	}
+	drift := max(nextDriftCheck(artifact), time.Second)
+	if next == 0 {
+		return drift
+	}
+	return min(next, drift)
+}
+
+// checkDrift asks the artifact's source whether the file changed since it
+// was downloaded and records the answer in the UpstreamChanged condition.
+// The file on disk is not touched; a failed check is logged and retried at
+// the next interval.
+func (r *BootArtifactReconciler) checkDrift(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact) error {
+	log := logf.FromContext(ctx)
+
+	changes, err := r.upstreamChanges(ctx, artifact)
+	now := metav1.Now()
+	artifact.Status.LastDriftCheck = &now
+	switch {
+	case err != nil:
+		log.Info("Drift check failed", "url", artifact.Status.SourceURL, "error", err.Error())
+	case len(changes) > 0:
+		msg := "Upstream file changed since download: " + strings.Join(changes, ", ")
+		if setCondition(&artifact.Status.Conditions, artifact.Generation,
+			isobootgithubiov1alpha1.BootArtifactConditionUpstreamChanged, true, reasonUpstreamChanged, msg) && r.Recorder != nil {
+			r.Recorder.Eventf(artifact, nil, "Warning", reasonUpstreamChanged, "DriftCheck", "%s", msg)
+		}
+	default:
+		setCondition(&artifact.Status.Conditions, artifact.Generation,
+			isobootgithubiov1alpha1.BootArtifactConditionUpstreamChanged, false, reasonUpstreamUnchanged, "")
+	}
+	return r.updateStatus(ctx, artifact)
+}
+
+// upstreamChanges compares the headers the artifact's source sends now with
+// its provenance and describes each difference. Headers that were not
+// recorded are not compared.
+func (r *BootArtifactReconciler) upstreamChanges(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact) ([]string, error) {
+	prov := artifact.Status.Provenance
+	url := cmp.Or(artifact.Status.EffectiveURL, artifact.Status.SourceURL)
+	if err := checkInsecureSource(artifact, url); err != nil {
+		return nil, err
+	}
+	httpClient, err := r.sourceClient(ctx, artifact)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := url
+	if strings.HasPrefix(url, fileScheme) {
+		root, fileClient, fileURL, err := r.openFileSource(url)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = root.Close() }()
+		httpClient, reqURL = fileClient, fileURL
+	}
+
+	resp, err := probeSource(ctx, httpClient, http.MethodHead, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		_ = resp.Body.Close()
+		// Ask for the body only if it changed, and close it unread.
+		header := http.Header{}
+		if prov.ETag != "" {
+			header.Set("If-None-Match", prov.ETag)
+		}
+		if prov.LastModified != "" {
+			header.Set("If-Modified-Since", prov.LastModified)
+		}
+		if resp, err = probeSource(ctx, httpClient, http.MethodGet, reqURL, header); err != nil {
+			return nil, err
+		}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	size := resp.ContentLength
+	if size < 0 {
+		// The file transport sends the header but leaves the field unset.
+		if n, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+			size = n
+		}
+	}
+	var changes []string
+	if etag := resp.Header.Get("ETag"); prov.ETag != "" && etag != prov.ETag {
+		changes = append(changes, fmt.Sprintf("ETag %q -> %q", prov.ETag, etag))
+	}
+	if lm := resp.Header.Get("Last-Modified"); prov.LastModified != "" && lm != prov.LastModified {
+		changes = append(changes, fmt.Sprintf("Last-Modified %q -> %q", prov.LastModified, lm))
+	}
+	if prov.ContentLength > 0 && size >= 0 && size != prov.ContentLength {
+		changes = append(changes, fmt.Sprintf("size %d -> %d bytes", prov.ContentLength, size))
+	}
+	return changes, nil
+}
+
+// probeSource sends a request for url's headers. The caller closes the
+// response body.
+func probeSource(ctx context.Context, httpClient *http.Client, method, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// clearDrift forgets the drift state of a file that was just replaced.
+func clearDrift(artifact *isobootgithubiov1alpha1.BootArtifact) {
+	artifact.Status.LastDriftCheck = nil
+	meta.RemoveStatusCondition(&artifact.Status.Conditions, isobootgithubiov1alpha1.BootArtifactConditionUpstreamChanged)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+var _ = Describe("BootArtifact drift check", func() {
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootArtifactReconciler
+		recorder   *events.FakeRecorder
+		etag       atomic.Pointer[string]
+		heads      atomic.Int32
+		gets       atomic.Int32
+		headStatus atomic.Int32
+		serverURL  string
+	)
+	content := []byte("drifting kernel")
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-drift-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		etag.Store(new(`"v1"`))
+		heads.Store(0)
+		gets.Store(0)
+		headStatus.Store(http.StatusOK)
+
+		var httpClient *http.Client
+		var cleanup func()
+		serverURL, httpClient, cleanup = withTestServer(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				heads.Add(1)
+				if code := int(headStatus.Load()); code != http.StatusOK {
+					w.WriteHeader(code)
+					return
+				}
+			} else {
+				gets.Add(1)
+			}
+			w.Header().Set("ETag", *etag.Load())
+			if r.Header.Get("If-None-Match") == *etag.Load() {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = w.Write(content)
+		})
+		DeferCleanup(cleanup)
+		recorder = events.NewFakeRecorder(10)
+		reconciler = &BootArtifactReconciler{
+			Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir, HTTPClient: httpClient, Recorder: recorder,
+		}
+	})
+	AfterEach(func() { Expect(os.RemoveAll(dataDir)).To(Succeed()) })
+
+	reconcileArtifact := func(name string) (reconcile.Result, isobootgithubiov1alpha1.BootArtifact) {
+		key := types.NamespacedName{Name: name, Namespace: "default"}
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		var artifact isobootgithubiov1alpha1.BootArtifact
+		ExpectWithOffset(1, k8sClient.Get(ctx, key, &artifact)).To(Succeed())
+		return result, artifact
+	}
+
+	// createDownloaded creates a drift-checked artifact, downloads it and
+	// makes its first drift check due.
+	createDownloaded := func(name string) {
+		artifact := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: isobootgithubiov1alpha1.BootArtifactSpec{
+				URL:                serverURL + "/vmlinuz",
+				SHA256:             new(sha256Hex(content)),
+				DriftCheckInterval: &metav1.Duration{Duration: time.Hour},
+			},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, artifact)).To(Succeed())
+		DeferCleanup(func() {
+			_ = k8sClient.Delete(ctx, artifact)
+			_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: "default"}})
+		})
+
+		result, downloaded := reconcileArtifact(name)
+		ExpectWithOffset(1, downloaded.Status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		ExpectWithOffset(1, result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+		ExpectWithOffset(1, recorder.Events).To(Receive(HavePrefix("Normal Downloaded")))
+
+		downloaded.Status.Provenance.CompletionTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+		ExpectWithOffset(1, k8sClient.Status().Update(ctx, &downloaded)).To(Succeed())
+	}
+
+	It("rejects intervals shorter than a minute", func() {
+		artifact := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: "drift-short", Namespace: "default"},
+			Spec: isobootgithubiov1alpha1.BootArtifactSpec{
+				URL: "https://example.com/vmlinuz", SHA256: new(validSHA256),
+				DriftCheckInterval: &metav1.Duration{Duration: 30 * time.Second},
+			},
+		}
+		Expect(k8sClient.Create(ctx, artifact)).NotTo(Succeed())
+	})
+
+	It("reports a changed upstream without touching the file", func() {
+		name := "drift-changed"
+		createDownloaded(name)
+		etag.Store(new(`"v2"`))
+
+		result, artifact := reconcileArtifact(name)
+		Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+		Expect(heads.Load()).To(Equal(int32(1)))
+		Expect(gets.Load()).To(Equal(int32(1)), "only the download")
+		Expect(artifact.Status.LastDriftCheck).NotTo(BeNil())
+		cond := meta.FindStatusCondition(artifact.Status.Conditions, isobootgithubiov1alpha1.BootArtifactConditionUpstreamChanged)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Message).To(ContainSubstring(`ETag "\"v1\"" -> "\"v2\""`))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning UpstreamChanged")))
+		Expect(artifact.Status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+
+		data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(content))
+	})
+
+	It("falls back to a conditional GET when HEAD is not allowed", func() {
+		name := "drift-unchanged"
+		createDownloaded(name)
+		headStatus.Store(http.StatusMethodNotAllowed)
+
+		_, artifact := reconcileArtifact(name)
+		Expect(heads.Load()).To(Equal(int32(1)))
+		Expect(gets.Load()).To(Equal(int32(2)))
+		cond := meta.FindStatusCondition(artifact.Status.Conditions, isobootgithubiov1alpha1.BootArtifactConditionUpstreamChanged)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(recorder.Events).NotTo(Receive())
+
+		// Not due again until the interval passes.
+		reconcileArtifact(name)
+		Expect(heads.Load()).To(Equal(int32(1)))
+	})
+})
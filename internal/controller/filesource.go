@@ -0,0 +1,79 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+const (
+	httpScheme = "http://"
+	fileScheme = "file://"
+)
+
+// checkInsecureSource rejects plain http:// and file:// sources unless the
+// artifact opts in with spec.allowInsecureSource. The API server enforces
+// the same rule; this guards objects created before it existed.
+func checkInsecureSource(artifact *isobootgithubiov1alpha1.BootArtifact, rawURL string) error {
+	if !strings.HasPrefix(rawURL, httpScheme) && !strings.HasPrefix(rawURL, fileScheme) {
+		return nil
+	}
+	if !artifact.Spec.AllowInsecureSource {
+		return errors.New("http:// and file:// sources require spec.allowInsecureSource")
+	}
+	if artifact.Spec.SHA256 == nil && artifact.Spec.SHA512 == nil {
+		return errors.New("http:// and file:// sources require a pinned sha256 or sha512")
+	}
+	return nil
+}
+
+// openFileSource opens FileSourceRoot and returns it with a client and
+// request URL that serve the file:// source from it. The file transport
+// supports Range requests and Last-Modified, so interrupted copies resume
+// like HTTP downloads. Paths outside the root, including through symlinks,
+// are refused.
+func (r *BootArtifactReconciler) openFileSource(rawURL string) (*os.Root, *http.Client, string, error) {
+	if r.FileSourceRoot == "" {
+		return nil, nil, "", errors.New("file:// sources are disabled: the controller has no --file-source-root")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("parsing url: %w", err)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, nil, "", fmt.Errorf("file url host %q is not supported", u.Host)
+	}
+	rel, err := filepath.Rel(r.FileSourceRoot, filepath.Clean(u.Path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return nil, nil, "", fmt.Errorf("%s is outside the file source root %s", u.Path, r.FileSourceRoot)
+	}
+	root, err := os.OpenRoot(r.FileSourceRoot)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("opening file source root: %w", err)
+	}
+	httpClient := &http.Client{Transport: http.NewFileTransportFS(root.FS())}
+	reqURL := (&url.URL{Scheme: "file", Path: "/" + filepath.ToSlash(rel)}).String()
+	return root, httpClient, reqURL, nil
+}
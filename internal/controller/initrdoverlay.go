@@ -0,0 +1,381 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+	"github.com/isoboot/isoboot/internal/urlutil"
+)
+
+const (
+	// initrdOverlayFile is the initrd overlay built in the boot directory,
+	// and initrdOverlayDigestFile the digest of the sources it was built
+	// from.
+	initrdOverlayFile       = ".initrd-overlay.cpio.gz"
+	initrdOverlayDigestFile = ".initrd-overlay.sha256"
+
+	// overlayResyncInterval is how often a Ready BootConfig with ConfigMap
+	// or Secret overlays is reconciled, since those are not watched.
+	overlayResyncInterval = time.Minute
+
+	// maxOverlayFileSize is the largest file a newc cpio archive can hold.
+	maxOverlayFileSize = 1<<32 - 1
+)
+
+// overlayError is a problem with spec.initrdOverlays, recorded in the
+// BootConfig's status with reason.
+type overlayError struct {
+	reason  string
+	message string
+}
+
+func (e *overlayError) Error() string { return e.message }
+
+// overlayFile is a file of the initrd overlay: name is its path in the
+// initramfs, and its contents are data or, for an artifact, the file at src.
+type overlayFile struct {
+	name    string
+	mode    uint32
+	data    []byte
+	src     string
+	size    int64
+	modTime time.Time
+}
+
+// open returns the contents of f.
+func (f overlayFile) open() (io.ReadCloser, error) {
+	if f.src != "" {
+		return os.Open(f.src)
+	}
+	return io.NopCloser(bytes.NewReader(f.data)), nil
+}
+
+// hasUnwatchedOverlays reports whether bc has overlays read from ConfigMaps
+// or Secrets.
+func hasUnwatchedOverlays(bc *isobootgithubiov1alpha1.BootConfig) bool {
+	return slices.ContainsFunc(bc.Spec.InitrdOverlays, func(o isobootgithubiov1alpha1.BootConfigInitrdOverlay) bool {
+		return o.ConfigMapKeyRef != nil || o.SecretKeyRef != nil
+	})
+}
+
+// setOverlayFailed records err from building the initrd overlay: Pending
+// while an overlay artifact is not Ready and Error for other problems with
+// spec.initrdOverlays. Other errors, from the API server, are returned.
+func (r *BootConfigReconciler) setOverlayFailed(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig, err error) (ctrl.Result, error) {
+	oe, ok := errors.AsType[*overlayError](err)
+	switch {
+	case !ok:
+		return ctrl.Result{}, err
+	case oe.reason == reasonArtifactPending:
+		return r.setPending(ctx, bc, oe.reason, oe.message)
+	default:
+		return r.setError(ctx, bc, oe.reason, oe.message)
+	}
+}
+
+// overlayExtractedInitrd writes overlaid/initrd, the initrd extracted into
+// bootDir followed by the initrd overlay, or removes it when bc has no
+// overlays.
+func (r *BootConfigReconciler) overlayExtractedInitrd(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig, bootDir string) error {
+	overlay, err := r.buildInitrdOverlay(ctx, bc, bootDir)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(bootDir, isobootgithubiov1alpha1.OverlaidInitrdDir)
+	if overlay == "" {
+		if err := os.RemoveAll(dir); err != nil {
+			return &overlayError{reasonAssemblyFailed, fmt.Sprintf("removing overlaid initrd: %v", err)}
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return &overlayError{reasonAssemblyFailed, fmt.Sprintf("creating overlaid initrd dir: %v", err)}
+	}
+	if err := concatenateFiles(filepath.Join(dir, "initrd"), filepath.Join(bootDir, "initrd"), overlay); err != nil {
+		return &overlayError{reasonAssemblyFailed, fmt.Sprintf("appending initrd overlay: %v", err)}
+	}
+	return nil
+}
+
+// buildInitrdOverlay writes the initrd overlay of bc into bootDir and
+// returns its path, or removes it and returns "" when bc has none. The
+// overlay is only rewritten when its sources change, so its modification
+// time tells concatenateFiles whether the served initrd is current.
+func (r *BootConfigReconciler) buildInitrdOverlay(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig, bootDir string) (string, error) {
+	overlayPath := filepath.Join(bootDir, initrdOverlayFile)
+	digestPath := filepath.Join(bootDir, initrdOverlayDigestFile)
+	if len(bc.Spec.InitrdOverlays) == 0 {
+		for _, p := range []string{overlayPath, digestPath} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return "", &overlayError{reasonAssemblyFailed, fmt.Sprintf("removing initrd overlay: %v", err)}
+			}
+		}
+		return "", nil
+	}
+
+	files, err := r.resolveInitrdOverlays(ctx, bc)
+	if err != nil {
+		return "", err
+	}
+	sum := overlayDigest(files)
+	if prev, err := os.ReadFile(digestPath); err == nil && string(prev) == sum {
+		if _, err := os.Stat(overlayPath); err == nil {
+			return overlayPath, nil // already built from these sources
+		}
+	}
+	if err := writeInitrdOverlay(overlayPath, files); err != nil {
+		return "", &overlayError{reasonAssemblyFailed, fmt.Sprintf("writing initrd overlay: %v", err)}
+	}
+	if err := os.WriteFile(digestPath, []byte(sum), 0o644); err != nil {
+		return "", &overlayError{reasonAssemblyFailed, fmt.Sprintf("writing initrd overlay digest: %v", err)}
+	}
+	return overlayPath, nil
+}
+
+// resolveInitrdOverlays reads the files of bc's initrd overlays from their
+// ConfigMaps, Secrets and BootArtifacts.
+func (r *BootConfigReconciler) resolveInitrdOverlays(ctx context.Context, bc *isobootgithubiov1alpha1.BootConfig) ([]overlayFile, error) {
+	names, err := overlayNames(bc.Spec.InitrdOverlays)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]overlayFile, 0, len(names))
+	for i, o := range bc.Spec.InitrdOverlays {
+		f := overlayFile{name: names[i], mode: 0o644}
+		if o.Mode != nil {
+			f.mode = uint32(*o.Mode) & 0o7777
+		}
+		switch {
+		case o.ConfigMapKeyRef != nil:
+			ref := o.ConfigMapKeyRef
+			var cm corev1.ConfigMap
+			if err := r.Get(ctx, client.ObjectKey{Namespace: bc.Namespace, Name: ref.Name}, &cm); err != nil {
+				if client.IgnoreNotFound(err) != nil {
+					return nil, err
+				}
+				return nil, &overlayError{reasonRefMissing, fmt.Sprintf("overlay ConfigMap %q not found", ref.Name)}
+			}
+			if data, ok := cm.Data[ref.Key]; ok {
+				f.data = []byte(data)
+			} else if data, ok := cm.BinaryData[ref.Key]; ok {
+				f.data = data
+			} else {
+				return nil, &overlayError{reasonRefMissing, fmt.Sprintf("overlay ConfigMap %q has no key %q", ref.Name, ref.Key)}
+			}
+		case o.SecretKeyRef != nil:
+			ref := o.SecretKeyRef
+			var secret corev1.Secret
+			if err := r.Get(ctx, client.ObjectKey{Namespace: bc.Namespace, Name: ref.Name}, &secret); err != nil {
+				if client.IgnoreNotFound(err) != nil {
+					return nil, err
+				}
+				return nil, &overlayError{reasonRefMissing, fmt.Sprintf("overlay Secret %q not found", ref.Name)}
+			}
+			data, ok := secret.Data[ref.Key]
+			if !ok {
+				return nil, &overlayError{reasonRefMissing, fmt.Sprintf("overlay Secret %q has no key %q", ref.Name, ref.Key)}
+			}
+			f.data = data
+		default:
+			artifact, err := r.getArtifact(ctx, o.ArtifactRef, bc.Namespace)
+			if err != nil {
+				if client.IgnoreNotFound(err) != nil {
+					return nil, err
+				}
+				return nil, &overlayError{reasonArtifactMissing, fmt.Sprintf("overlay artifact %q not found", o.ArtifactRef)}
+			}
+			if artifact.Status.Phase != isobootgithubiov1alpha1.BootArtifactPhaseReady {
+				return nil, &overlayError{reasonArtifactPending, fmt.Sprintf("waiting for overlay artifact %q to be Ready", o.ArtifactRef)}
+			}
+			filename := urlutil.ArtifactFilename(artifact.Spec.URL, string(artifact.Spec.Decompress))
+			f.src = filepath.Join(r.DataDir, "artifacts", artifact.Name, filename)
+			info, err := os.Stat(f.src)
+			if err != nil {
+				return nil, &overlayError{reasonAssemblyFailed, fmt.Sprintf("stat overlay artifact %q: %v", o.ArtifactRef, err)}
+			}
+			if info.Size() > maxOverlayFileSize {
+				return nil, &overlayError{reasonAssemblyFailed,
+					fmt.Sprintf("overlay artifact %q exceeds the cpio file size limit of %d bytes", o.ArtifactRef, int64(maxOverlayFileSize))}
+			}
+			f.size, f.modTime = info.Size(), info.ModTime()
+		}
+		if f.src == "" {
+			f.size = int64(len(f.data))
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// overlayNames returns the paths of overlays relative to the initramfs
+// root, rejecting paths with traversal, paths that repeat, and files that
+// would be the parent directory of another.
+func overlayNames(overlays []isobootgithubiov1alpha1.BootConfigInitrdOverlay) ([]string, error) {
+	names := make([]string, 0, len(overlays))
+	for _, o := range overlays {
+		name := cleanArchivePath(o.Path)
+		if !isSafeISOPath(o.Path) || name == "." {
+			return nil, &overlayError{reasonInvalidPath, fmt.Sprintf("invalid initrdOverlays path %q: path traversal not allowed", o.Path)}
+		}
+		for _, other := range names {
+			if other == name || strings.HasPrefix(other, name+"/") || strings.HasPrefix(name, other+"/") {
+				return nil, &overlayError{reasonInvalidPath, fmt.Sprintf("invalid initrdOverlays path %q: conflicts with %q", o.Path, other)}
+			}
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// overlayDigest identifies the contents of an overlay built from files:
+// their names, modes and data, or the size and modification time of an
+// artifact's file, which is replaced rather than modified in place.
+func overlayDigest(files []overlayFile) string {
+	h := sha256.New()
+	for _, f := range files {
+		_, _ = fmt.Fprintf(h, "%s\x00%o\x00", f.name, f.mode)
+		if f.src != "" {
+			_, _ = fmt.Fprintf(h, "artifact\x00%s\x00%d\x00%d\x00", f.src, f.size, f.modTime.UnixNano())
+		} else {
+			_, _ = fmt.Fprintf(h, "data\x00%d\x00", len(f.data))
+			_, _ = h.Write(f.data)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeInitrdOverlay writes files to dst atomically as a gzip-compressed
+// newc cpio archive.
+func writeInitrdOverlay(dst string, files []overlayFile) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".overlay-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+	}()
+
+	zw := gzip.NewWriter(tmp)
+	if err := writeCpio(zw, files); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compressing: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0o444); err != nil {
+		return fmt.Errorf("setting permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, dst); err != nil {
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+	return nil
+}
+
+// cpio file type bits of the mode field.
+const (
+	cpioModeDir     = 0o040000
+	cpioModeRegular = 0o100000
+)
+
+// writeCpio writes files to w as a newc cpio archive, the format the Linux
+// kernel unpacks an initramfs from, preceded by their parent directories.
+// Entries are owned by root and have no modification time, so the archive
+// only depends on files.
+func writeCpio(w io.Writer, files []overlayFile) error {
+	var dirs []string
+	for _, f := range files {
+		for dir := path.Dir(f.name); dir != "."; dir = path.Dir(dir) {
+			if !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	// Sorted, a directory comes before its subdirectories.
+	slices.Sort(dirs)
+
+	var ino uint32
+	for _, dir := range dirs {
+		ino++
+		if err := writeCpioHeader(w, ino, cpioModeDir|0o755, 2, 0, dir); err != nil {
+			return err
+		}
+	}
+	for _, f := range files {
+		ino++
+		if err := writeCpioHeader(w, ino, cpioModeRegular|f.mode, 1, f.size, f.name); err != nil {
+			return err
+		}
+		rc, err := f.open()
+		if err != nil {
+			return fmt.Errorf("opening %q: %w", f.name, err)
+		}
+		_, err = io.CopyN(w, rc, f.size)
+		_ = rc.Close()
+		if err != nil {
+			return fmt.Errorf("copying %q: %w", f.name, err)
+		}
+		if err := writeCpioPadding(w, f.size); err != nil {
+			return err
+		}
+	}
+	return writeCpioHeader(w, 0, 0, 1, 0, "TRAILER!!!")
+}
+
+// writeCpioHeader writes a newc header and the NUL-terminated name, padded
+// to a multiple of four bytes.
+func writeCpioHeader(w io.Writer, ino, mode, nlink uint32, size int64, name string) error {
+	// magic, then ino, mode, uid, gid, nlink, mtime, filesize, devmajor,
+	// devminor, rdevmajor, rdevminor, namesize and check in hex.
+	hdr := fmt.Sprintf("070701%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+		ino, mode, 0, 0, nlink, 0, size, 0, 0, 0, 0, len(name)+1, 0)
+	if _, err := io.WriteString(w, hdr+name+"\x00"); err != nil {
+		return fmt.Errorf("writing cpio header: %w", err)
+	}
+	return writeCpioPadding(w, int64(len(hdr)+len(name)+1))
+}
+
+// writeCpioPadding writes the NUL bytes that align n bytes to four.
+func writeCpioPadding(w io.Writer, n int64) error {
+	if _, err := w.Write(make([]byte, (4-n%4)%4)); err != nil {
+		return fmt.Errorf("writing cpio padding: %w", err)
+	}
+	return nil
+}
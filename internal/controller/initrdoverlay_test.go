@@ -0,0 +1,129 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// cpioEntry is an entry read back from a test cpio archive.
+type cpioEntry struct {
+	mode    uint32
+	content string
+}
+
+// readTestCpio parses the gzip-compressed newc cpio archive in data into
+// its entries by name, in order, up to the trailer.
+func readTestCpio(data []byte) ([]string, map[string]cpioEntry, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, nil, err
+	}
+	field := func(hdr []byte, i int) int {
+		v, _ := strconv.ParseUint(string(hdr[6+8*i:14+8*i]), 16, 32)
+		return int(v)
+	}
+	align := func(n int) int { return (n + 3) &^ 3 }
+	var names []string
+	entries := map[string]cpioEntry{}
+	for off := 0; ; {
+		if off+110 > len(raw) || string(raw[off:off+6]) != "070701" {
+			return nil, nil, fmt.Errorf("bad cpio header at offset %d", off)
+		}
+		hdr := raw[off : off+110]
+		mode, size, nameSize := field(hdr, 1), field(hdr, 6), field(hdr, 11)
+		name := string(raw[off+110 : off+110+nameSize-1])
+		if name == "TRAILER!!!" {
+			return names, entries, nil
+		}
+		dataOff := off + align(110+nameSize)
+		names = append(names, name)
+		entries[name] = cpioEntry{mode: uint32(mode), content: string(raw[dataOff : dataOff+size])}
+		off = dataOff + align(size)
+	}
+}
+
+var _ = Describe("initrd overlays", func() {
+	It("writes a gzip-compressed newc cpio archive with parent directories", func() {
+		dir := GinkgoT().TempDir()
+		dud := filepath.Join(dir, "dd.iso")
+		Expect(os.WriteFile(dud, []byte("DRIVER-DISK"), 0o644)).To(Succeed())
+		overlay := filepath.Join(dir, "overlay.cpio.gz")
+		Expect(writeInitrdOverlay(overlay, []overlayFile{
+			{name: "ks.cfg", mode: 0o644, data: []byte("text\n"), size: 5},
+			{name: "etc/pki/anchors/corp.pem", mode: 0o600, data: []byte("PEM"), size: 3},
+			{name: "dd.iso", mode: 0o644, src: dud, size: 11},
+		})).To(Succeed())
+
+		data, err := os.ReadFile(overlay)
+		Expect(err).NotTo(HaveOccurred())
+		names, entries, err := readTestCpio(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"etc", "etc/pki", "etc/pki/anchors", "ks.cfg", "etc/pki/anchors/corp.pem", "dd.iso"}))
+		Expect(entries["etc/pki"]).To(Equal(cpioEntry{mode: cpioModeDir | 0o755}))
+		Expect(entries["ks.cfg"]).To(Equal(cpioEntry{mode: cpioModeRegular | 0o644, content: "text\n"}))
+		Expect(entries["etc/pki/anchors/corp.pem"]).To(Equal(cpioEntry{mode: cpioModeRegular | 0o600, content: "PEM"}))
+		Expect(entries["dd.iso"].content).To(Equal("DRIVER-DISK"))
+	})
+
+	It("normalizes overlay paths and rejects traversal and conflicts", func() {
+		overlays := func(paths ...string) []isobootgithubiov1alpha1.BootConfigInitrdOverlay {
+			var out []isobootgithubiov1alpha1.BootConfigInitrdOverlay
+			for _, p := range paths {
+				out = append(out, isobootgithubiov1alpha1.BootConfigInitrdOverlay{Path: p})
+			}
+			return out
+		}
+		names, err := overlayNames(overlays("/ks.cfg", "etc//hosts", "./usr/bin/setup.sh"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"ks.cfg", "etc/hosts", "usr/bin/setup.sh"}))
+
+		for _, paths := range [][]string{
+			{"../etc/shadow"},
+			{"/"},
+			{"/ks.cfg", "ks.cfg"},
+			{"etc/hosts", "etc"},
+			{"etc", "etc/hosts"},
+		} {
+			_, err := overlayNames(overlays(paths...))
+			Expect(err).To(HaveOccurred(), "%v", paths)
+		}
+	})
+
+	It("changes the digest with the contents of any file", func() {
+		files := []overlayFile{{name: "ks.cfg", mode: 0o644, data: []byte("a")}}
+		sum := overlayDigest(files)
+		Expect(overlayDigest([]overlayFile{{name: "ks.cfg", mode: 0o644, data: []byte("a")}})).To(Equal(sum))
+		Expect(overlayDigest([]overlayFile{{name: "ks.cfg", mode: 0o644, data: []byte("b")}})).NotTo(Equal(sum))
+		Expect(overlayDigest([]overlayFile{{name: "ks.cfg", mode: 0o600, data: []byte("a")}})).NotTo(Equal(sum))
+	})
+})
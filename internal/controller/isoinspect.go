@@ -0,0 +1,296 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// maxISOMetadataSize caps the metadata and boot loader files read from an
+// ISO while inspecting it.
+const maxISOMetadataSize = 1 << 20 // 1 MiB
+
+// maxISOListing caps the top-level entries recorded in status.iso.files.
+const maxISOListing = 64
+
+// inspectISO reports what fsys, the root of an ISO, contains: the distro and
+// version named by .treeinfo or .disk/info, the kernel and initrd to boot,
+// the kernel arguments of the default boot entry, less those bound to the
+// boot media, and a listing of the top level. Boot files are taken from the
+// first source naming a kernel and initrd that both exist: .treeinfo,
+// boot/grub/grub.cfg, EFI/BOOT/grub.cfg, isolinux/isolinux.cfg, then the
+// casper/ directory of Ubuntu live ISOs. Anything not found is left empty.
+func inspectISO(fsys fs.FS) *isobootgithubiov1alpha1.BootConfigISOStatus {
+	status := &isobootgithubiov1alpha1.BootConfigISOStatus{}
+
+	var found bool
+	setBoot := func(kernel, initrd string) {
+		kernel, initrd = cleanISOPath(kernel), cleanISOPath(initrd)
+		if found || !isoFileExists(fsys, kernel) || !isoFileExists(fsys, initrd) {
+			return
+		}
+		status.KernelPath, status.InitrdPath, found = kernel, initrd, true
+	}
+
+	if data, ok := readISOFile(fsys, ".treeinfo"); ok {
+		ti := parseTreeinfo(data)
+		status.Distro, status.Version = ti.distro, ti.version
+		setBoot(ti.kernel, ti.initrd)
+	}
+	if status.Distro == "" {
+		if data, ok := readISOFile(fsys, ".disk/info"); ok {
+			status.Distro, status.Version = parseDiskInfo(data)
+		}
+	}
+
+	var argsFound bool
+	for _, cfg := range []struct {
+		name  string
+		parse func(data []byte, dir string) (kernel, initrd, args string)
+	}{
+		{"boot/grub/grub.cfg", parseGrubConfig},
+		{"EFI/BOOT/grub.cfg", parseGrubConfig},
+		{"isolinux/isolinux.cfg", parseIsolinuxConfig},
+	} {
+		data, ok := readISOFile(fsys, cfg.name)
+		if !ok {
+			continue
+		}
+		kernel, initrd, args := cfg.parse(data, path.Dir(cfg.name))
+		if !argsFound && isoFileExists(fsys, cleanISOPath(kernel)) {
+			status.KernelArgs, argsFound = stripMediaArgs(args), true
+		}
+		setBoot(kernel, initrd)
+	}
+	for _, initrd := range []string{"casper/initrd", "casper/initrd.gz", "casper/initrd.lz"} {
+		setBoot("casper/vmlinuz", initrd)
+	}
+
+	if entries, err := fs.ReadDir(fsys, "."); err == nil {
+		for _, e := range entries[:min(len(entries), maxISOListing)] {
+			name := e.Name()
+			if name == "" {
+				continue
+			}
+			if e.IsDir() {
+				name += "/"
+			}
+			status.Files = append(status.Files, name)
+		}
+	}
+	return status
+}
+
+// stripMediaArgs removes from args those that find or check the disc the
+// ISO was written to, such as inst.stage2=hd:LABEL=..., root=live:CDLABEL=...
+// and rd.live.check. A network boot has no such disc, and the installer
+// waits for it until it times out.
+func stripMediaArgs(args string) string {
+	return strings.Join(slices.DeleteFunc(strings.Fields(args), func(arg string) bool {
+		key, value, _ := strings.Cut(arg, "=")
+		switch key {
+		case "inst.stage2", "inst.repo", "stage2", "repo":
+			return strings.HasPrefix(value, "hd:") || strings.HasPrefix(value, "cdrom")
+		case "root":
+			return strings.Contains(value, "CDLABEL=")
+		case "file":
+			return strings.HasPrefix(value, "/cdrom/")
+		case "rd.live.check", "inst.check", "mediacheck":
+			return true
+		}
+		return false
+	}), " ")
+}
+
+// cleanISOPath returns p relative to the ISO root, or "" when it is empty,
+// uses a boot loader variable, or leaves the ISO.
+func cleanISOPath(p string) string {
+	p = path.Clean(strings.TrimPrefix(p, "/"))
+	if p == "." || strings.Contains(p, "$") || !isSafeISOPath(p) {
+		return ""
+	}
+	return p
+}
+
+// isoFileExists reports whether p names a regular file in fsys.
+func isoFileExists(fsys fs.FS, p string) bool {
+	if p == "" {
+		return false
+	}
+	info, err := fs.Stat(fsys, p)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// readISOFile returns the contents of name in fsys, up to
+// maxISOMetadataSize bytes. go-diskfs drops the leading dot of file names,
+// so .treeinfo is also looked up as treeinfo.
+func readISOFile(fsys fs.FS, name string) ([]byte, bool) {
+	f, err := fsys.Open(name)
+	if dir, file := path.Split(name); err != nil && strings.HasPrefix(file, ".") {
+		f, err = fsys.Open(dir + file[1:])
+	}
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(io.LimitReader(f, maxISOMetadataSize))
+	return data, err == nil
+}
+
+// treeinfo is what inspectISO uses from a .treeinfo file.
+type treeinfo struct {
+	distro, version, kernel, initrd string
+}
+
+// parseTreeinfo reads the .treeinfo INI file of Fedora, RHEL and derived
+// ISOs. The distro and version come from [release], or [general] in the
+// older format, and the boot files from the [images-<arch>] section.
+func parseTreeinfo(data []byte) treeinfo {
+	sections := map[string]map[string]string{}
+	var firstImages string
+	var current map[string]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if sections[name] == nil {
+				sections[name] = map[string]string{}
+			}
+			current = sections[name]
+			if firstImages == "" && strings.HasPrefix(name, "images-") {
+				firstImages = name
+			}
+		case current != nil:
+			if k, v, ok := strings.Cut(line, "="); ok {
+				current[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+
+	var ti treeinfo
+	if rel := sections["release"]; rel != nil {
+		ti.distro, ti.version = rel["name"], rel["version"]
+	}
+	if general := sections["general"]; general != nil && ti.distro == "" {
+		ti.distro, ti.version = general["family"], general["version"]
+	}
+	arch := sections["tree"]["arch"]
+	if arch == "" {
+		arch = sections["general"]["arch"]
+	}
+	images := sections["images-"+arch]
+	if images == nil {
+		images = sections[firstImages]
+	}
+	ti.kernel, ti.initrd = images["kernel"], images["initrd"]
+	return ti
+}
+
+// parseDiskInfo reads the one-line .disk/info of Debian and Ubuntu ISOs,
+// such as `Ubuntu-Server 24.04.1 LTS "Noble Numbat" - Release amd64`. The
+// version starts at the first word beginning with a digit; the words
+// before it are the distro.
+func parseDiskInfo(data []byte) (distro, version string) {
+	line, _, _ := strings.Cut(string(data), "\n")
+	line, _, _ = strings.Cut(line, `"`)
+	words := strings.Fields(line)
+	for i, w := range words {
+		if w[0] >= '0' && w[0] <= '9' {
+			return strings.Join(words[:i], " "), strings.Join(words[i:], " ")
+		}
+	}
+	return strings.Join(words, " "), ""
+}
+
+// parseGrubConfig returns the kernel, initrd and kernel arguments of the
+// first linux command in a GRUB configuration, which belongs to the default
+// menu entry, and the first initrd command after it.
+func parseGrubConfig(data []byte, _ string) (kernel, initrd, args string) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "linux", "linuxefi", "linux16":
+			if kernel != "" {
+				return kernel, initrd, args
+			}
+			kernel, args = fields[1], strings.Join(fields[2:], " ")
+		case "initrd", "initrdefi", "initrd16":
+			if kernel != "" {
+				// Later initrds are usually microcode or overlays; the
+				// first is the installer's.
+				return kernel, fields[1], args
+			}
+		}
+	}
+	return kernel, initrd, args
+}
+
+// parseIsolinuxConfig returns the kernel, initrd and remaining kernel
+// arguments of the first label in an isolinux configuration in dir. The
+// initrd is given in its append line; relative paths are relative to dir.
+func parseIsolinuxConfig(data []byte, dir string) (kernel, initrd, args string) {
+	resolve := func(p string) string {
+		if p == "" || strings.HasPrefix(p, "/") {
+			return p
+		}
+		return path.Join(dir, p)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch strings.ToLower(fields[0]) {
+		case "kernel", "linux":
+			if kernel != "" {
+				return resolve(kernel), resolve(initrd), args
+			}
+			kernel = fields[1]
+		case "append":
+			if kernel == "" {
+				continue
+			}
+			var rest []string
+			for _, f := range fields[1:] {
+				if v, ok := strings.CutPrefix(f, "initrd="); ok && initrd == "" {
+					// initrd=a,b loads several; the first is the installer's.
+					initrd, _, _ = strings.Cut(v, ",")
+					continue
+				}
+				rest = append(rest, f)
+			}
+			args = strings.Join(rest, " ")
+		}
+	}
+	return resolve(kernel), resolve(initrd), args
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing/fstest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// isoFS returns an in-memory ISO root holding files.
+func isoFS(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+var _ = Describe("inspectISO", func() {
+	It("reads a Rocky Linux .treeinfo and isolinux configuration", func() {
+		status := inspectISO(isoFS(map[string]string{
+			".treeinfo": `[general]
+family = Rocky Linux
+version = 9.4
+arch = x86_64
+
+[release]
+name = Rocky Linux
+version = 9.4
+
+[tree]
+arch = x86_64
+
+[images-xen]
+kernel = images/pxeboot/xen-vmlinuz
+
+[images-x86_64]
+kernel = images/pxeboot/vmlinuz
+initrd = images/pxeboot/initrd.img
+`,
+			"images/pxeboot/vmlinuz":    "k",
+			"images/pxeboot/initrd.img": "i",
+			"isolinux/vmlinuz":          "k",
+			"isolinux/initrd.img":       "i",
+			"isolinux/isolinux.cfg": `default vesamenu.c32
+label linux
+  menu label ^Install Rocky Linux 9.4
+  kernel vmlinuz
+  append initrd=initrd.img inst.stage2=hd:LABEL=Rocky-9-4-x86_64-dvd quiet
+label check
+  kernel vmlinuz
+  append initrd=initrd.img rd.live.check
+`,
+		}))
+		Expect(status).To(Equal(&isobootgithubiov1alpha1.BootConfigISOStatus{
+			Distro:     "Rocky Linux",
+			Version:    "9.4",
+			KernelPath: "images/pxeboot/vmlinuz",
+			InitrdPath: "images/pxeboot/initrd.img",
+			KernelArgs: "quiet",
+			Files:      []string{".treeinfo", "images/", "isolinux/"},
+		}))
+	})
+
+	It("reads an Ubuntu .disk/info and GRUB configuration", func() {
+		status := inspectISO(isoFS(map[string]string{
+			".disk/info": `Ubuntu-Server 24.04.1 LTS "Noble Numbat" - Release amd64 (20240827.1)`,
+			"boot/grub/grub.cfg": `set timeout=30
+menuentry "Try or Install Ubuntu Server" {
+	set gfxpayload=keep
+	linux	/casper/vmlinuz  ---
+	initrd	/casper/initrd
+}
+menuentry "Ubuntu Server with the HWE kernel" {
+	linux	/casper/hwe-vmlinuz  ---
+	initrd	/casper/hwe-initrd
+}
+`,
+			"casper/vmlinuz": "k",
+			"casper/initrd":  "i",
+		}))
+		Expect(status.Distro).To(Equal("Ubuntu-Server"))
+		Expect(status.Version).To(Equal("24.04.1 LTS"))
+		Expect(status.KernelPath).To(Equal("casper/vmlinuz"))
+		Expect(status.InitrdPath).To(Equal("casper/initrd"))
+		Expect(status.KernelArgs).To(Equal("---"))
+	})
+
+	It("drops the kernel arguments bound to the boot media", func() {
+		Expect(stripMediaArgs("root=live:CDLABEL=Fedora-WS-Live-40 rd.live.image rd.live.check quiet")).
+			To(Equal("rd.live.image quiet"))
+		Expect(stripMediaArgs("inst.stage2=hd:LABEL=Rocky inst.repo=cdrom inst.text")).To(Equal("inst.text"))
+		Expect(stripMediaArgs("inst.stage2=https://mirror.example.com/rocky/9/BaseOS/x86_64/os")).
+			To(Equal("inst.stage2=https://mirror.example.com/rocky/9/BaseOS/x86_64/os"))
+		Expect(stripMediaArgs("file=/cdrom/preseed/ubuntu.seed boot=casper ---")).To(Equal("boot=casper ---"))
+	})
+
+	It("falls back to casper/ and skips boot files that do not exist", func() {
+		status := inspectISO(isoFS(map[string]string{
+			"boot/grub/grub.cfg": "menuentry x {\n linux ($root)/boot/vmlinuz-$kver quiet\n initrd /boot/initrd\n}\n",
+			"casper/vmlinuz":     "k",
+			"casper/initrd.gz":   "i",
+		}))
+		Expect(status.KernelPath).To(Equal("casper/vmlinuz"))
+		Expect(status.InitrdPath).To(Equal("casper/initrd.gz"))
+	})
+
+	It("finds nothing in an ISO without boot files", func() {
+		status := inspectISO(isoFS(map[string]string{"README": "hello"}))
+		Expect(status.KernelPath).To(BeEmpty())
+		Expect(status.InitrdPath).To(BeEmpty())
+		Expect(status.Files).To(Equal([]string{"README"}))
+	})
+})
@@ -0,0 +1,119 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// MachineReconciler reconciles a Machine object
+type MachineReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=isoboot.github.io,resources=machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=isoboot.github.io,resources=machines/status,verbs=get;update;patch
+
+func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var machine isobootgithubiov1alpha1.Machine
+	if err := r.Get(ctx, req.NamespacedName, &machine); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	duplicates, err := r.sameMAC(ctx, &machine)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing machines: %w", err)
+	}
+	ready, reason, message := true, reasonValid, ""
+	if len(duplicates) > 0 {
+		ready, reason = false, reasonDuplicateMAC
+		message = fmt.Sprintf("MAC address %s is also used by %s", machine.Spec.MAC, strings.Join(duplicates, ", "))
+	}
+
+	changed := machine.Status.ObservedGeneration != machine.Generation
+	machine.Status.ObservedGeneration = machine.Generation
+	if setCondition(&machine.Status.Conditions, machine.Generation,
+		isobootgithubiov1alpha1.ConditionReady, ready, reason, message) {
+		changed = true
+	}
+	if changed {
+		if err := r.Status().Update(ctx, &machine); err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// sameMAC returns the names of the other Machines in the namespace with the
+// machine's MAC address, in any case.
+func (r *MachineReconciler) sameMAC(ctx context.Context, machine *isobootgithubiov1alpha1.Machine) ([]string, error) {
+	var machines isobootgithubiov1alpha1.MachineList
+	if err := r.List(ctx, &machines, client.InNamespace(machine.Namespace)); err != nil {
+		return nil, err
+	}
+	var names []string
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		if m.Name != machine.Name && strings.EqualFold(m.Spec.MAC, machine.Spec.MAC) {
+			names = append(names, m.Name)
+		}
+	}
+	return names, nil
+}
+
+// findMachinesWithSameMAC wakes the other Machines sharing a MAC address
+// with a changed one, so their Ready condition follows.
+func (r *MachineReconciler) findMachinesWithSameMAC(ctx context.Context, obj client.Object) []reconcile.Request {
+	machine, ok := obj.(*isobootgithubiov1alpha1.Machine)
+	if !ok {
+		return nil
+	}
+	var machines isobootgithubiov1alpha1.MachineList
+	if err := r.List(ctx, &machines, client.InNamespace(machine.Namespace)); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		if m.Name != machine.Name && strings.EqualFold(m.Spec.MAC, machine.Spec.MAC) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(m)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&isobootgithubiov1alpha1.Machine{}).
+		Watches(&isobootgithubiov1alpha1.Machine{}, handler.EnqueueRequestsFromMapFunc(
+			r.findMachinesWithSameMAC,
+		)).
+		Named("machine").
+		Complete(r)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+var _ = Describe("Machine Controller", func() {
+	ctx := context.Background()
+
+	createMachine := func(name, mac string) *isobootgithubiov1alpha1.Machine {
+		m := &isobootgithubiov1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       isobootgithubiov1alpha1.MachineSpec{MAC: mac},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, m)).To(Succeed())
+		return m
+	}
+	readyCondition := func(reconciler *MachineReconciler, name string) *metav1.Condition {
+		key := types.NamespacedName{Name: name, Namespace: "default"}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		var m isobootgithubiov1alpha1.Machine
+		ExpectWithOffset(1, k8sClient.Get(ctx, key, &m)).To(Succeed())
+		ExpectWithOffset(1, m.Status.ObservedGeneration).To(Equal(m.Generation))
+		return meta.FindStatusCondition(m.Status.Conditions, isobootgithubiov1alpha1.ConditionReady)
+	}
+
+	It("is Ready unless another Machine has the same MAC address", func() {
+		reconciler := &MachineReconciler{Client: k8sClient, Scheme: scheme.Scheme}
+
+		first := createMachine("machine-first", "aa-bb-cc-dd-ee-10")
+		defer func() { Expect(k8sClient.Delete(ctx, first)).To(Succeed()) }()
+		ready := readyCondition(reconciler, "machine-first")
+		Expect(ready).NotTo(BeNil())
+		Expect(ready.Status).To(Equal(metav1.ConditionTrue))
+
+		second := createMachine("machine-second", "AA-BB-CC-DD-EE-10")
+		ready = readyCondition(reconciler, "machine-second")
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Reason).To(Equal("DuplicateMAC"))
+		Expect(ready.Message).To(ContainSubstring("machine-first"))
+		Expect(reconciler.findMachinesWithSameMAC(ctx, second)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "machine-first", Namespace: "default"}},
+		))
+
+		Expect(k8sClient.Delete(ctx, second)).To(Succeed())
+		Expect(readyCondition(reconciler, "machine-first").Status).To(Equal(metav1.ConditionTrue))
+	})
+})
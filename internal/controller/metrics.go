@@ -0,0 +1,192 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// Failure reasons for the isoboot_artifact_failures_total metric.
+const (
+	failureReasonDigest       = "DigestResolution"
+	failureReasonFilesystem   = "Filesystem"
+	failureReasonHashMismatch = "HashMismatch"
+	failureReasonDownload     = "DownloadFailed"
+	failureReasonDiskPressure = "DiskPressure"
+)
+
+var (
+	downloadedBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "isoboot_artifact_downloaded_bytes_total",
+		Help: "Bytes received from BootArtifact download sources.",
+	})
+	downloadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "isoboot_artifact_download_duration_seconds",
+		Help:    "Duration of BootArtifact download attempts, per source, by result.",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 14),
+	}, []string{"result"})
+	hashMismatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "isoboot_artifact_hash_mismatches_total",
+		Help: "BootArtifact digest mismatches, after a download or when verifying a stored file.",
+	}, []string{"stage"})
+	artifactFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "isoboot_artifact_failures_total",
+		Help: "BootArtifact reconcile failures by reason.",
+	}, []string{"reason"})
+	hashDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "isoboot_artifact_hash_duration_seconds",
+		Help:    "Time spent hashing stored BootArtifact files for verification.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 14),
+	})
+	isoExtractionDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "isoboot_bootconfig_iso_extraction_duration_seconds",
+		Help:    "Time spent extracting the kernel and initrd from a BootConfig's ISO.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(downloadedBytes, downloadDuration, hashMismatches,
+		artifactFailures, hashDuration, isoExtractionDuration)
+}
+
+var (
+	bootArtifactsDesc = prometheus.NewDesc("isoboot_bootartifacts",
+		"Number of BootArtifacts by phase.", []string{"phase"}, nil)
+	bootArtifactFailureCountDesc = prometheus.NewDesc("isoboot_bootartifact_failure_count",
+		"Consecutive failures of a BootArtifact, as in status.failureCount.", []string{"namespace", "name"}, nil)
+	bootConfigsDesc = prometheus.NewDesc("isoboot_bootconfigs",
+		"Number of BootConfigs by phase.", []string{"phase"}, nil)
+)
+
+var (
+	bootArtifactPhases = []isobootgithubiov1alpha1.BootArtifactPhase{
+		isobootgithubiov1alpha1.BootArtifactPhasePending,
+		isobootgithubiov1alpha1.BootArtifactPhaseQueued,
+		isobootgithubiov1alpha1.BootArtifactPhaseDownloading,
+		isobootgithubiov1alpha1.BootArtifactPhaseReady,
+		isobootgithubiov1alpha1.BootArtifactPhaseError,
+		isobootgithubiov1alpha1.BootArtifactPhaseEvicted,
+	}
+	bootConfigPhases = []isobootgithubiov1alpha1.BootConfigPhase{
+		isobootgithubiov1alpha1.BootConfigPhasePending,
+		isobootgithubiov1alpha1.BootConfigPhaseReady,
+		isobootgithubiov1alpha1.BootConfigPhaseError,
+	}
+)
+
+// phaseCollector reports BootArtifact and BootConfig counts by phase, and
+// each BootArtifact's failureCount, from the objects at scrape time.
+type phaseCollector struct {
+	reader client.Reader
+}
+
+// NewPhaseCollector returns a collector for the isoboot_bootartifacts,
+// isoboot_bootartifact_failure_count and isoboot_bootconfigs metrics, read
+// through reader (normally the manager's cached client).
+func NewPhaseCollector(reader client.Reader) prometheus.Collector {
+	return &phaseCollector{reader: reader}
+}
+
+func (c *phaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bootArtifactsDesc
+	ch <- bootArtifactFailureCountDesc
+	ch <- bootConfigsDesc
+}
+
+func (c *phaseCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var artifacts isobootgithubiov1alpha1.BootArtifactList
+	if err := c.reader.List(ctx, &artifacts); err == nil {
+		counts := map[isobootgithubiov1alpha1.BootArtifactPhase]int{}
+		for i := range artifacts.Items {
+			a := &artifacts.Items[i]
+			phase := a.Status.Phase
+			if phase == "" {
+				phase = isobootgithubiov1alpha1.BootArtifactPhasePending
+			}
+			counts[phase]++
+			ch <- prometheus.MustNewConstMetric(bootArtifactFailureCountDesc, prometheus.GaugeValue,
+				float64(a.Status.FailureCount), a.Namespace, a.Name)
+		}
+		for _, phase := range bootArtifactPhases {
+			ch <- prometheus.MustNewConstMetric(bootArtifactsDesc, prometheus.GaugeValue, float64(counts[phase]), string(phase))
+		}
+	}
+
+	var configs isobootgithubiov1alpha1.BootConfigList
+	if err := c.reader.List(ctx, &configs); err == nil {
+		counts := map[isobootgithubiov1alpha1.BootConfigPhase]int{}
+		for i := range configs.Items {
+			phase := configs.Items[i].Status.Phase
+			if phase == "" {
+				phase = isobootgithubiov1alpha1.BootConfigPhasePending
+			}
+			counts[phase]++
+		}
+		for _, phase := range bootConfigPhases {
+			ch <- prometheus.MustNewConstMetric(bootConfigsDesc, prometheus.GaugeValue, float64(counts[phase]), string(phase))
+		}
+	}
+}
+
+var (
+	dataDirAvailableBytesDesc = prometheus.NewDesc("isoboot_data_dir_available_bytes",
+		"Bytes available in the data directory's filesystem.", nil, nil)
+	dataDirDiskPressureDesc = prometheus.NewDesc("isoboot_data_dir_disk_pressure",
+		"1 while the data directory has less free space than --min-free-space, otherwise 0.", nil, nil)
+)
+
+// dataDirCollector reports the data directory's free space at scrape time.
+type dataDirCollector struct {
+	dataDir      string
+	minFreeSpace int64
+}
+
+// NewDataDirCollector returns a collector for the
+// isoboot_data_dir_available_bytes and isoboot_data_dir_disk_pressure
+// metrics of dataDir.
+func NewDataDirCollector(dataDir string, minFreeSpace int64) prometheus.Collector {
+	return &dataDirCollector{dataDir: dataDir, minFreeSpace: minFreeSpace}
+}
+
+func (c *dataDirCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dataDirAvailableBytesDesc
+	ch <- dataDirDiskPressureDesc
+}
+
+func (c *dataDirCollector) Collect(ch chan<- prometheus.Metric) {
+	available, err := availableSpace(c.dataDir)
+	if err != nil {
+		return
+	}
+	pressure := 0.0
+	if available < c.minFreeSpace {
+		pressure = 1
+	}
+	ch <- prometheus.MustNewConstMetric(dataDirAvailableBytesDesc, prometheus.GaugeValue, float64(available))
+	ch <- prometheus.MustNewConstMetric(dataDirDiskPressureDesc, prometheus.GaugeValue, pressure)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+var _ = Describe("phaseCollector", func() {
+	It("reports counts by phase and failure counts", func() {
+		artifact := func(name string, phase isobootgithubiov1alpha1.BootArtifactPhase, failures int32) *isobootgithubiov1alpha1.BootArtifact {
+			return &isobootgithubiov1alpha1.BootArtifact{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Status:     isobootgithubiov1alpha1.BootArtifactStatus{Phase: phase, FailureCount: failures},
+			}
+		}
+		reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			artifact("kernel", isobootgithubiov1alpha1.BootArtifactPhaseReady, 0),
+			artifact("initrd", isobootgithubiov1alpha1.BootArtifactPhaseError, 3),
+			artifact("new", "", 0),
+			&isobootgithubiov1alpha1.BootConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "debian", Namespace: "default"},
+				Status:     isobootgithubiov1alpha1.BootConfigStatus{Phase: isobootgithubiov1alpha1.BootConfigPhasePending},
+			},
+		).Build()
+
+		expected := `
+# HELP isoboot_bootartifact_failure_count Consecutive failures of a BootArtifact, as in status.failureCount.
+# TYPE isoboot_bootartifact_failure_count gauge
+isoboot_bootartifact_failure_count{name="initrd",namespace="default"} 3
+isoboot_bootartifact_failure_count{name="kernel",namespace="default"} 0
+isoboot_bootartifact_failure_count{name="new",namespace="default"} 0
+# HELP isoboot_bootartifacts Number of BootArtifacts by phase.
+# TYPE isoboot_bootartifacts gauge
+isoboot_bootartifacts{phase="Downloading"} 0
+isoboot_bootartifacts{phase="Error"} 1
+isoboot_bootartifacts{phase="Evicted"} 0
+isoboot_bootartifacts{phase="Pending"} 1
+isoboot_bootartifacts{phase="Queued"} 0
+isoboot_bootartifacts{phase="Ready"} 1
+# HELP isoboot_bootconfigs Number of BootConfigs by phase.
+# TYPE isoboot_bootconfigs gauge
+isoboot_bootconfigs{phase="Error"} 0
+isoboot_bootconfigs{phase="Pending"} 1
+isoboot_bootconfigs{phase="Ready"} 0
+`
+		Expect(testutil.CollectAndCompare(NewPhaseCollector(reader), strings.NewReader(expected))).To(Succeed())
+	})
+})
+
+var _ = Describe("dataDirCollector", func() {
+	It("reports disk pressure below the minimum free space", func() {
+		dataDir := GinkgoT().TempDir()
+		pressure := `
+# HELP isoboot_data_dir_disk_pressure 1 while the data directory has less free space than --min-free-space, otherwise 0.
+# TYPE isoboot_data_dir_disk_pressure gauge
+isoboot_data_dir_disk_pressure %d
+`
+		Expect(testutil.CollectAndCompare(NewDataDirCollector(dataDir, 0),
+			strings.NewReader(fmt.Sprintf(pressure, 0)), "isoboot_data_dir_disk_pressure")).To(Succeed())
+		Expect(testutil.CollectAndCompare(NewDataDirCollector(dataDir, 1<<62),
+			strings.NewReader(fmt.Sprintf(pressure, 1)), "isoboot_data_dir_disk_pressure")).To(Succeed())
+		Expect(testutil.CollectAndCount(NewDataDirCollector(dataDir, 0), "isoboot_data_dir_available_bytes")).To(Equal(1))
+	})
+})
@@ -0,0 +1,82 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// urlRewriter holds the rules of every ArtifactMirrorPolicy, in the order
+// they are tried: longest from prefix first, then by policy name and rule
+// order.
+type urlRewriter []isobootgithubiov1alpha1.URLRewriteRule
+
+// urlRewriter loads the ArtifactMirrorPolicy rules.
+func (r *BootArtifactReconciler) urlRewriter(ctx context.Context) (urlRewriter, error) {
+	var policies isobootgithubiov1alpha1.ArtifactMirrorPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return nil, fmt.Errorf("listing ArtifactMirrorPolicies: %w", err)
+	}
+	slices.SortFunc(policies.Items, func(a, b isobootgithubiov1alpha1.ArtifactMirrorPolicy) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	var rw urlRewriter
+	for _, p := range policies.Items {
+		rw = append(rw, p.Spec.Rules...)
+	}
+	slices.SortStableFunc(rw, func(a, b isobootgithubiov1alpha1.URLRewriteRule) int {
+		return cmp.Compare(len(b.From), len(a.From))
+	})
+	return rw, nil
+}
+
+// rewrite applies the first matching rule to rawURL. It returns rawURL
+// unchanged when no rule matches.
+func (rw urlRewriter) rewrite(rawURL string) string {
+	for _, rule := range rw {
+		if rest, ok := strings.CutPrefix(rawURL, rule.From); ok {
+			return rule.To + rest
+		}
+	}
+	return rawURL
+}
+
+// findFailedArtifactsForPolicy retries the artifacts in the Error phase when
+// an ArtifactMirrorPolicy changes, since a new rule may point them at a
+// reachable mirror.
+func (r *BootArtifactReconciler) findFailedArtifactsForPolicy(ctx context.Context, _ client.Object) []reconcile.Request {
+	var artifacts isobootgithubiov1alpha1.BootArtifactList
+	if err := r.List(ctx, &artifacts); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range artifacts.Items {
+		if artifacts.Items[i].Status.Phase == isobootgithubiov1alpha1.BootArtifactPhaseError {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&artifacts.Items[i])})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+var _ = Describe("ArtifactMirrorPolicy", func() {
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootArtifactReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-mirrorpolicy-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &BootArtifactReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir}
+	})
+	AfterEach(func() { Expect(os.RemoveAll(dataDir)).To(Succeed()) })
+
+	makePolicy := func(name string, rules ...isobootgithubiov1alpha1.URLRewriteRule) {
+		policy := &isobootgithubiov1alpha1.ArtifactMirrorPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       isobootgithubiov1alpha1.ArtifactMirrorPolicySpec{Rules: rules},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, policy)).To(Succeed())
+		DeferCleanup(func() { Expect(k8sClient.Delete(ctx, policy)).To(Succeed()) })
+	}
+
+	It("rejects policies without rules or with path traversal", func() {
+		for name, rules := range map[string][]isobootgithubiov1alpha1.URLRewriteRule{
+			"no-rules":  nil,
+			"traversal": {{From: "https://deb.debian.org/", To: "https://mirror.internal/../etc/"}},
+			"ftp":       {{From: "https://deb.debian.org/", To: "ftp://mirror.internal/"}},
+		} {
+			policy := &isobootgithubiov1alpha1.ArtifactMirrorPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       isobootgithubiov1alpha1.ArtifactMirrorPolicySpec{Rules: rules},
+			}
+			Expect(k8sClient.Create(ctx, policy)).NotTo(Succeed(), name)
+		}
+	})
+
+	It("applies the longest matching prefix across policies", func() {
+		makePolicy("b-site",
+			isobootgithubiov1alpha1.URLRewriteRule{From: "https://deb.debian.org/debian/", To: "https://b.internal/debian/"},
+			isobootgithubiov1alpha1.URLRewriteRule{From: "https://cdn.example.com/", To: "https://b.internal/cdn/"},
+		)
+		makePolicy("a-site",
+			isobootgithubiov1alpha1.URLRewriteRule{From: "https://deb.debian.org/", To: "https://a.internal/"},
+			isobootgithubiov1alpha1.URLRewriteRule{From: "https://cdn.example.com/", To: "https://a.internal/cdn/"},
+		)
+
+		rw, err := reconciler.urlRewriter(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rw.rewrite("https://deb.debian.org/debian/dists/vmlinuz")).To(Equal("https://b.internal/debian/dists/vmlinuz"))
+		Expect(rw.rewrite("https://deb.debian.org/other/vmlinuz")).To(Equal("https://a.internal/other/vmlinuz"))
+		Expect(rw.rewrite("https://cdn.example.com/vmlinuz")).To(Equal("https://a.internal/cdn/vmlinuz"))
+		Expect(rw.rewrite("https://example.org/vmlinuz")).To(Equal("https://example.org/vmlinuz"))
+	})
+
+	It("downloads from the rewritten URL and records it in status", func() {
+		content := []byte("site mirror kernel")
+		var requested atomic.Pointer[string]
+		serverURL, httpClient, cleanup := withTestServer(func(w http.ResponseWriter, r *http.Request) {
+			requested.Store(new(r.URL.Path))
+			_, _ = w.Write(content)
+		})
+		defer cleanup()
+		reconciler.HTTPClient = httpClient
+		makePolicy("site", isobootgithubiov1alpha1.URLRewriteRule{From: "https://upstream.invalid/", To: serverURL + "/mirror/"})
+
+		name := "dl-rewritten"
+		artifact := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       isobootgithubiov1alpha1.BootArtifactSpec{URL: "https://upstream.invalid/debian/vmlinuz", SHA256: new(sha256Hex(content))},
+		}
+		Expect(k8sClient.Create(ctx, artifact)).To(Succeed())
+		defer func() {
+			_ = k8sClient.Delete(ctx, artifact)
+			_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: "default"}})
+		}()
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, artifact)).To(Succeed())
+		Expect(artifact.Status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		Expect(artifact.Status.SourceURL).To(Equal("https://upstream.invalid/debian/vmlinuz"))
+		Expect(artifact.Status.EffectiveURL).To(Equal(serverURL + "/mirror/debian/vmlinuz"))
+		Expect(*requested.Load()).To(Equal("/mirror/debian/vmlinuz"))
+
+		data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", name, "vmlinuz"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(content))
+	})
+})
@@ -0,0 +1,370 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// ociScheme prefixes BootArtifact URLs that name an OCI artifact.
+const ociScheme = "oci://"
+
+// maxManifestSize bounds OCI manifest and token responses.
+const maxManifestSize = 4 << 20
+
+// Manifest media types accepted from registries. Image indexes are not: a
+// BootArtifact is one file, so the reference must resolve to one manifest.
+var ociManifestTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var (
+	ociRepositoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	ociTagPattern        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+	// challengeParam matches one key="value" pair of a WWW-Authenticate header.
+	challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// ociReference is a parsed oci://<registry>/<repository>[:<tag>|@<digest>].
+type ociReference struct {
+	registry   string
+	repository string
+	// reference is the tag, or the digest in "<algorithm>:<hex>" form.
+	reference string
+	// pinned is set when reference is a digest.
+	pinned bool
+}
+
+// parseOCIReference parses an oci:// URL. A missing tag means "latest".
+func parseOCIReference(raw string) (ociReference, error) {
+	rest, ok := strings.CutPrefix(raw, ociScheme)
+	if !ok {
+		return ociReference{}, fmt.Errorf("%q is not an oci:// reference", raw)
+	}
+	registry, name, ok := strings.Cut(rest, "/")
+	if !ok || registry == "" || name == "" {
+		return ociReference{}, fmt.Errorf("%q has no repository", raw)
+	}
+	ref := ociReference{registry: registry, repository: name, reference: "latest"}
+	if repo, d, ok := strings.Cut(name, "@"); ok {
+		if _, valid := parseDigest(d); !valid {
+			return ociReference{}, fmt.Errorf("%q has an invalid digest", raw)
+		}
+		ref.repository, ref.reference, ref.pinned = repo, d, true
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.repository, ref.reference = name[:i], name[i+1:]
+		if !ociTagPattern.MatchString(ref.reference) {
+			return ociReference{}, fmt.Errorf("%q has an invalid tag", raw)
+		}
+	}
+	if !ociRepositoryPattern.MatchString(ref.repository) {
+		return ociReference{}, fmt.Errorf("%q has an invalid repository name", raw)
+	}
+	return ref, nil
+}
+
+// apiURL returns the registry API URL for path under the repository.
+func (ref ociReference) apiURL(path string) string {
+	return "https://" + ref.registry + "/v2/" + ref.repository + "/" + path
+}
+
+// ociLayer is the single layer of an OCI artifact and how to download it.
+type ociLayer struct {
+	digest digest
+	size   int64
+	url    string
+	// header carries the registry authorization for the blob request.
+	header http.Header
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// resolveOCILayer fetches the manifest for an oci:// URL and returns its
+// layer. The artifact must consist of exactly one layer.
+func (r *BootArtifactReconciler) resolveOCILayer(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, rawURL string) (ociLayer, error) {
+	ref, err := parseOCIReference(rawURL)
+	if err != nil {
+		return ociLayer{}, err
+	}
+	username, password, err := r.registryCredentials(ctx, artifact, ref.registry)
+	if err != nil {
+		return ociLayer{}, err
+	}
+	httpClient, err := r.sourceClient(ctx, artifact)
+	if err != nil {
+		return ociLayer{}, err
+	}
+	reg := &ociRegistry{httpClient: httpClient, ref: ref, username: username, password: password}
+
+	resp, err := reg.get(ctx, ref.apiURL("manifests/"+ref.reference), strings.Join(ociManifestTypes, ", "))
+	if err != nil {
+		return ociLayer{}, fmt.Errorf("fetching manifest: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		return ociLayer{}, fmt.Errorf("fetching manifest: HTTP %d", resp.StatusCode)
+	}
+	body, err := readLimited(resp.Body, maxManifestSize)
+	if err != nil {
+		return ociLayer{}, fmt.Errorf("reading manifest: %w", err)
+	}
+	if ref.pinned {
+		want, _ := parseDigest(ref.reference)
+		h := want.newHash()
+		h.Write(body)
+		if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want.hex) {
+			return ociLayer{}, fmt.Errorf("manifest digest mismatch: expected %s got %s:%s", want, want.algorithm, got)
+		}
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return ociLayer{}, fmt.Errorf("parsing manifest: %w", err)
+	}
+	mediaType := manifest.MediaType
+	if mediaType == "" {
+		mediaType, _, _ = strings.Cut(resp.Header.Get("Content-Type"), ";")
+	}
+	if mediaType != "" && !slices.Contains(ociManifestTypes, mediaType) {
+		return ociLayer{}, fmt.Errorf("unsupported manifest type %q; reference a single-platform manifest", mediaType)
+	}
+	if len(manifest.Layers) != 1 {
+		return ociLayer{}, fmt.Errorf("artifact has %d layers, expected exactly one", len(manifest.Layers))
+	}
+	layer := manifest.Layers[0]
+	d, ok := parseDigest(layer.Digest)
+	if !ok {
+		return ociLayer{}, fmt.Errorf("unsupported layer digest %q", layer.Digest)
+	}
+
+	header := http.Header{}
+	if reg.authorization != "" {
+		header.Set("Authorization", reg.authorization)
+	}
+	return ociLayer{digest: d, size: layer.Size, url: ref.apiURL("blobs/" + d.String()), header: header}, nil
+}
+
+// registryCredentials returns the username and password for registry from
+// spec.imagePullSecret, or empty strings for anonymous access.
+func (r *BootArtifactReconciler) registryCredentials(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, registry string) (string, string, error) {
+	ref := artifact.Spec.ImagePullSecret
+	if ref == nil {
+		return "", "", nil
+	}
+	var secret corev1.Secret
+	if err := r.Get(ctx, client.ObjectKey{Namespace: artifact.Namespace, Name: ref.Name}, &secret); err != nil {
+		return "", "", fmt.Errorf("getting image pull Secret %q: %w", ref.Name, err)
+	}
+
+	var auths map[string]dockerAuth
+	if data, ok := secret.Data[corev1.DockerConfigJsonKey]; ok {
+		var config struct {
+			Auths map[string]dockerAuth `json:"auths"`
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return "", "", fmt.Errorf("parsing image pull Secret %q: %w", ref.Name, err)
+		}
+		auths = config.Auths
+	} else if data, ok := secret.Data[corev1.DockerConfigKey]; ok {
+		if err := json.Unmarshal(data, &auths); err != nil {
+			return "", "", fmt.Errorf("parsing image pull Secret %q: %w", ref.Name, err)
+		}
+	} else {
+		return "", "", fmt.Errorf("image pull Secret %q has no %s or %s key", ref.Name, corev1.DockerConfigJsonKey, corev1.DockerConfigKey)
+	}
+
+	for server, auth := range auths {
+		if registryHost(server) != registry {
+			continue
+		}
+		username, password := auth.Username, auth.Password
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return "", "", fmt.Errorf("decoding auth for %q in image pull Secret %q: %w", server, ref.Name, err)
+			}
+			username, password, _ = strings.Cut(string(decoded), ":")
+		}
+		return username, password, nil
+	}
+	return "", "", nil
+}
+
+// dockerAuth is one registry entry of a Docker config file.
+type dockerAuth struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// registryHost reduces a Docker config server key such as
+// "https://registry.example.com/v1/" to its host.
+func registryHost(server string) string {
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		return u.Host
+	}
+	host, _, _ := strings.Cut(server, "/")
+	return host
+}
+
+// ociRegistry performs requests against one repository, answering the
+// registry's Basic or Bearer token challenge on the first 401.
+type ociRegistry struct {
+	httpClient         *http.Client
+	ref                ociReference
+	username, password string
+	// authorization is the Authorization header value once authenticated.
+	authorization string
+}
+
+func (c *ociRegistry) get(ctx context.Context, rawURL, accept string) (*http.Response, error) {
+	resp, err := c.do(ctx, rawURL, accept)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || c.authorization != "" {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	_ = resp.Body.Close()
+	if err := c.authorize(ctx, challenge); err != nil {
+		return nil, err
+	}
+	return c.do(ctx, rawURL, accept)
+}
+
+func (c *ociRegistry) do(ctx context.Context, rawURL, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	return c.httpClient.Do(req)
+}
+
+// authorize sets c.authorization from a WWW-Authenticate challenge.
+func (c *ociRegistry) authorize(ctx context.Context, challenge string) error {
+	scheme, rest, _ := strings.Cut(challenge, " ")
+	params := map[string]string{}
+	for _, m := range challengeParam.FindAllStringSubmatch(rest, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.username == "" {
+			return fmt.Errorf("registry %s requires credentials; set spec.imagePullSecret", c.ref.registry)
+		}
+		c.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(c.username+":"+c.password))
+		return nil
+	case "bearer":
+	default:
+		return fmt.Errorf("registry %s returned HTTP 401 with unsupported challenge %q", c.ref.registry, challenge)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme != "https" {
+		return fmt.Errorf("registry %s returned invalid token realm %q", c.ref.registry, params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + c.ref.repository + ":pull"
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return fmt.Errorf("creating token request: %w", err)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting registry token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		return fmt.Errorf("requesting registry token: HTTP %d", resp.StatusCode)
+	}
+	body, err := readLimited(resp.Body, maxManifestSize)
+	if err != nil {
+		return fmt.Errorf("reading registry token: %w", err)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return fmt.Errorf("parsing registry token: %w", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return fmt.Errorf("registry %s returned an empty token", c.ref.registry)
+	}
+	c.authorization = "Bearer " + token.Token
+	return nil
+}
+
+// readLimited reads r, refusing anything larger than limit bytes.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("larger than %d bytes", limit)
+	}
+	return data, nil
+}
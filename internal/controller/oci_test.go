@@ -0,0 +1,233 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+var _ = Describe("parseOCIReference", func() {
+	sha := sha256Hex([]byte("manifest"))
+
+	DescribeTable("parses references",
+		func(raw string, want ociReference) {
+			got, err := parseOCIReference(raw)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(want))
+		},
+		Entry("tag", "oci://registry.example.com/debian/vmlinuz:13", ociReference{"registry.example.com", "debian/vmlinuz", "13", false}),
+		Entry("registry port", "oci://localhost:5000/vmlinuz:v1.0", ociReference{"localhost:5000", "vmlinuz", "v1.0", false}),
+		Entry("default tag", "oci://registry.example.com/debian/vmlinuz", ociReference{"registry.example.com", "debian/vmlinuz", "latest", false}),
+		Entry("digest", "oci://registry.example.com/debian/vmlinuz@sha256:"+sha, ociReference{"registry.example.com", "debian/vmlinuz", "sha256:" + sha, true}),
+	)
+
+	DescribeTable("rejects invalid references",
+		func(raw string) {
+			_, err := parseOCIReference(raw)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("https url", "https://registry.example.com/vmlinuz"),
+		Entry("no repository", "oci://registry.example.com"),
+		Entry("uppercase repository", "oci://registry.example.com/Debian/vmlinuz:13"),
+		Entry("bad digest", "oci://registry.example.com/vmlinuz@sha256:abc"),
+		Entry("bad tag", "oci://registry.example.com/vmlinuz:-13"),
+	)
+})
+
+var _ = Describe("BootArtifact Controller OCI sources", func() {
+	const (
+		username = "puller"
+		password = "s3cret"
+		token    = "test-token"
+	)
+
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootArtifactReconciler
+		registry   string
+		cleanup    func()
+		content    = []byte("kernel from a registry")
+	)
+
+	// The stand-in registry serves isoboot/vmlinuz:1.0 as a single-layer
+	// artifact and isoboot/vmlinuz:multi with two layers, behind the Docker
+	// token flow with fixed credentials.
+	layer := fmt.Sprintf(`{"mediaType":"application/vnd.oci.image.layer.v1.tar","digest":"sha256:%s","size":%d}`, sha256Hex(content), len(content))
+	manifests := map[string]string{
+		"1.0":   `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[` + layer + `]}`,
+		"multi": `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[` + layer + `,` + layer + `]}`,
+	}
+	manifests["sha256:"+sha256Hex([]byte(manifests["1.0"]))] = manifests["1.0"]
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-oci-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &BootArtifactReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir}
+
+		var serverURL string
+		serverURL, reconciler.HTTPClient, cleanup = withTestServer(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = fmt.Fprintf(w, `{"token":%q}`, token)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="https://%s/token",service="test"`, r.Host))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/v2/isoboot/vmlinuz/manifests/"):
+				manifest, ok := manifests[strings.TrimPrefix(r.URL.Path, "/v2/isoboot/vmlinuz/manifests/")]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+				_, _ = w.Write([]byte(manifest))
+			case r.URL.Path == "/v2/isoboot/vmlinuz/blobs/sha256:"+sha256Hex(content):
+				_, _ = w.Write(content)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		registry = strings.TrimPrefix(serverURL, "https://")
+	})
+	AfterEach(func() {
+		cleanup()
+		Expect(os.RemoveAll(dataDir)).To(Succeed())
+	})
+
+	doReconcile := func(name string) (reconcile.Result, error) {
+		return reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+		})
+	}
+	getStatus := func(name string) isobootgithubiov1alpha1.BootArtifactStatus {
+		var a isobootgithubiov1alpha1.BootArtifact
+		ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)).To(Succeed())
+		return a.Status
+	}
+
+	makePullSecret := func(name string) func() {
+		auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: fmt.Appendf(nil, `{"auths":{"https://%s":{"auth":%q}}}`, registry, auth),
+			},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, secret)).To(Succeed())
+		return func() { _ = k8sClient.Delete(ctx, secret) }
+	}
+
+	makeArtifact := func(name string, spec isobootgithubiov1alpha1.BootArtifactSpec) func() {
+		a := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       spec,
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, a)).To(Succeed())
+		return func() {
+			_ = k8sClient.Delete(ctx, a)
+			// Run the finalizer so the object is actually removed.
+			_, _ = doReconcile(name)
+		}
+	}
+
+	It("pulls a single-layer artifact with registry credentials", func() {
+		defer makePullSecret("oci-ok-pull")()
+		defer makeArtifact("oci-ok", isobootgithubiov1alpha1.BootArtifactSpec{
+			URL:             "oci://" + registry + "/isoboot/vmlinuz:1.0",
+			ImagePullSecret: &isobootgithubiov1alpha1.SecretReference{Name: "oci-ok-pull"},
+		})()
+
+		result, err := doReconcile("oci-ok")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		status := getStatus("oci-ok")
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		Expect(status.ResolvedDigest).To(Equal("sha256:" + sha256Hex(content)))
+		data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", "oci-ok", "vmlinuz"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(content))
+	})
+
+	It("pulls a reference pinned by manifest digest", func() {
+		defer makePullSecret("oci-pinned-pull")()
+		defer makeArtifact("oci-pinned", isobootgithubiov1alpha1.BootArtifactSpec{
+			URL:             "oci://" + registry + "/isoboot/vmlinuz@sha256:" + sha256Hex([]byte(manifests["1.0"])),
+			SHA256:          new(sha256Hex(content)),
+			ImagePullSecret: &isobootgithubiov1alpha1.SecretReference{Name: "oci-pinned-pull"},
+		})()
+
+		_, err := doReconcile("oci-pinned")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getStatus("oci-pinned").Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+	})
+
+	It("fails without credentials for a private registry", func() {
+		defer makeArtifact("oci-anon", isobootgithubiov1alpha1.BootArtifactSpec{
+			URL: "oci://" + registry + "/isoboot/vmlinuz:1.0",
+		})()
+
+		result, err := doReconcile("oci-anon")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).NotTo(BeZero())
+		status := getStatus("oci-anon")
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+		Expect(status.Message).To(ContainSubstring("token"))
+	})
+
+	It("rejects artifacts with more than one layer", func() {
+		defer makePullSecret("oci-multi-pull")()
+		defer makeArtifact("oci-multi", isobootgithubiov1alpha1.BootArtifactSpec{
+			URL:             "oci://" + registry + "/isoboot/vmlinuz:multi",
+			ImagePullSecret: &isobootgithubiov1alpha1.SecretReference{Name: "oci-multi-pull"},
+		})()
+
+		_, err := doReconcile("oci-multi")
+		Expect(err).NotTo(HaveOccurred())
+		status := getStatus("oci-multi")
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+		Expect(status.Message).To(ContainSubstring("2 layers"))
+		_, err = os.Stat(filepath.Join(dataDir, "artifacts", "oci-multi", "vmlinuz"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
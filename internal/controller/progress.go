@@ -0,0 +1,100 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// defaultProgressInterval is how often download progress is written to the
+// BootArtifact status when BootArtifactReconciler.ProgressInterval is unset.
+const defaultProgressInterval = 10 * time.Second
+
+// progressReporter is an io.Writer that counts the bytes of a download and
+// patches status.progress at most once per interval, so multi-GB transfers
+// are visible without flooding the API server.
+type progressReporter struct {
+	ctx      context.Context
+	writer   client.SubResourceWriter
+	artifact *isobootgithubiov1alpha1.BootArtifact
+	interval time.Duration
+
+	received  int64
+	total     int64
+	lastBytes int64
+	lastTime  time.Time
+}
+
+func (r *BootArtifactReconciler) newProgressReporter(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact, received, total int64) *progressReporter {
+	interval := r.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	p := &progressReporter{
+		ctx:      ctx,
+		writer:   r.Status(),
+		artifact: artifact,
+		interval: interval,
+		received: received,
+		total:    total,
+	}
+	p.publish(time.Now())
+	return p
+}
+
+func (p *progressReporter) Write(b []byte) (int, error) {
+	p.received += int64(len(b))
+	if now := time.Now(); now.Sub(p.lastTime) >= p.interval {
+		p.publish(now)
+	}
+	return len(b), nil
+}
+
+// publish patches the current progress into the artifact status. Failures
+// are logged and otherwise ignored; progress is informational only.
+func (p *progressReporter) publish(now time.Time) {
+	progress := &isobootgithubiov1alpha1.BootArtifactProgress{
+		ReceivedBytes: p.received,
+		TotalBytes:    p.total,
+	}
+	if elapsed := now.Sub(p.lastTime); !p.lastTime.IsZero() && elapsed > 0 {
+		progress.BytesPerSecond = int64(float64(p.received-p.lastBytes) / elapsed.Seconds())
+	}
+	if p.total > 0 {
+		progress.Percent = int32(min(p.received*100/p.total, 100))
+		if progress.BytesPerSecond > 0 && p.received < p.total {
+			eta := time.Duration((p.total-p.received)/progress.BytesPerSecond) * time.Second
+			progress.ETA = &metav1.Duration{Duration: eta}
+		}
+	}
+	p.lastTime, p.lastBytes = now, p.received
+
+	// A merge patch carries no resourceVersion, so progress updates do not
+	// conflict with spec edits made during a long download.
+	patch := client.MergeFrom(p.artifact.DeepCopy())
+	p.artifact.Status.Progress = progress
+	if err := p.writer.Patch(p.ctx, p.artifact, patch); err != nil {
+		logf.FromContext(p.ctx).Info("Could not update download progress", "error", err.Error())
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// finalURL returns the URL resp was served from for the provenance record.
+// A file:// source is recorded as given, since the request went to a URL
+// relative to FileSourceRoot. The query string and user info are dropped:
+// registry and object store redirects carry short-lived signatures there.
+func finalURL(url string, resp *http.Response) string {
+	if strings.HasPrefix(url, fileScheme) || resp.Request == nil {
+		return url
+	}
+	u := *resp.Request.URL
+	u.User, u.RawQuery, u.ForceQuery, u.Fragment = nil, "", false, ""
+	return u.String()
+}
+
+// recordProvenance stores the provenance of a download from url in the
+// artifact status and emits an event summarizing it. When the previous
+// download came from the same source, an upstream that now redirects
+// elsewhere or serves a different ETag or Last-Modified is reported as well:
+// the content matched the expected digest both times, but the file moved or
+// was republished.
+func (r *BootArtifactReconciler) recordProvenance(artifact *isobootgithubiov1alpha1.BootArtifact, url string, prov *isobootgithubiov1alpha1.BootArtifactProvenance) {
+	prev := artifact.Status.Provenance
+	artifact.Status.Provenance = prov
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(artifact, nil, "Normal", "Downloaded", "Download",
+		"Downloaded %d bytes with digest %s from %s (ETag %q, Last-Modified %q) in %s",
+		prov.ContentLength, prov.Digest, prov.FinalURL, prov.ETag, prov.LastModified,
+		prov.CompletionTime.Sub(prov.StartTime.Time).Round(time.Millisecond))
+
+	if prev == nil || artifact.Status.SourceURL != url {
+		return
+	}
+	var changes []string
+	for _, c := range []struct{ name, was, now string }{
+		{"final URL", prev.FinalURL, prov.FinalURL},
+		{"ETag", prev.ETag, prov.ETag},
+		{"Last-Modified", prev.LastModified, prov.LastModified},
+	} {
+		if c.was != "" && c.was != c.now {
+			changes = append(changes, fmt.Sprintf("%s %q -> %q", c.name, c.was, c.now))
+		}
+	}
+	if len(changes) > 0 {
+		r.Recorder.Eventf(artifact, nil, "Normal", "SourceChanged", "Download",
+			"Upstream changed since the previous download: %s", strings.Join(changes, ", "))
+	}
+}
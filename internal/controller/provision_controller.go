@@ -18,11 +18,17 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
 )
@@ -35,6 +41,8 @@ type ProvisionReconciler struct {
 
 // +kubebuilder:rbac:groups=isoboot.github.io,resources=provisions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=isoboot.github.io,resources=provisions/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=isoboot.github.io,resources=machines;bootconfigs;provisionautomations,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get
 
 func (r *ProvisionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
@@ -44,21 +52,101 @@ func (r *ProvisionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	changed := false
 	if prov.Status.Phase == "" {
 		log.Info("Initializing Provision phase", "name", prov.Name)
 		prov.Status.Phase = isobootgithubiov1alpha1.ProvisionPhasePending
+		changed = true
+	}
+
+	reason, unresolved, err := r.resolveReferences(ctx, &prov)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if prov.Status.ObservedGeneration != prov.Generation {
+		prov.Status.ObservedGeneration = prov.Generation
+		changed = true
+	}
+	if setProvisionConditions(&prov, reason, unresolved) {
+		changed = true
+	}
+	if changed {
 		if err := r.Status().Update(ctx, &prov); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
+	if unresolved != "" {
+		// ConfigMaps and Secrets are not watched.
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// resolveReferences looks up every object the provision names. It returns
+// the reason and message for the first one that does not exist, or empty
+// strings when all do.
+func (r *ProvisionReconciler) resolveReferences(ctx context.Context, prov *isobootgithubiov1alpha1.Provision) (string, string, error) {
+	type reference struct {
+		reason, kind, name string
+		obj                client.Object
+	}
+	refs := []reference{
+		{"MachineNotFound", "machine", prov.Spec.MachineRef, &isobootgithubiov1alpha1.Machine{}},
+		{"BootConfigNotFound", "boot config", prov.Spec.BootConfigRef, &isobootgithubiov1alpha1.BootConfig{}},
+		{"ProvisionAutomationNotFound", "provision automation", prov.Spec.ProvisionAutomationRef, &isobootgithubiov1alpha1.ProvisionAutomation{}},
+	}
+	for _, name := range prov.Spec.ConfigMaps {
+		refs = append(refs, reference{"ConfigMapNotFound", "configmap", name, &corev1.ConfigMap{}})
+	}
+	for _, name := range prov.Spec.Secrets {
+		refs = append(refs, reference{"SecretNotFound", "secret", name, &corev1.Secret{}})
+	}
+	for _, ref := range refs {
+		err := r.Get(ctx, client.ObjectKey{Namespace: prov.Namespace, Name: ref.name}, ref.obj)
+		if apierrors.IsNotFound(err) {
+			return ref.reason, fmt.Sprintf("%s %q not found", ref.kind, ref.name), nil
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("getting %s %q: %w", ref.kind, ref.name, err)
+		}
+	}
+	return "", "", nil
+}
+
+// findProvisionsReferencing returns a map function that enqueues the
+// Provisions in the object's namespace whose ref(p) names it.
+func (r *ProvisionReconciler) findProvisionsReferencing(ref func(*isobootgithubiov1alpha1.Provision) string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var provisions isobootgithubiov1alpha1.ProvisionList
+		if err := r.List(ctx, &provisions, client.InNamespace(obj.GetNamespace())); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range provisions.Items {
+			if ref(&provisions.Items[i]) == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: client.ObjectKeyFromObject(&provisions.Items[i]),
+				})
+			}
+		}
+		return requests
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ProvisionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&isobootgithubiov1alpha1.Provision{}).
+		Watches(&isobootgithubiov1alpha1.Machine{}, handler.EnqueueRequestsFromMapFunc(
+			r.findProvisionsReferencing(func(p *isobootgithubiov1alpha1.Provision) string { return p.Spec.MachineRef }),
+		)).
+		Watches(&isobootgithubiov1alpha1.BootConfig{}, handler.EnqueueRequestsFromMapFunc(
+			r.findProvisionsReferencing(func(p *isobootgithubiov1alpha1.Provision) string { return p.Spec.BootConfigRef }),
+		)).
+		Watches(&isobootgithubiov1alpha1.ProvisionAutomation{}, handler.EnqueueRequestsFromMapFunc(
+			r.findProvisionsReferencing(func(p *isobootgithubiov1alpha1.Provision) string { return p.Spec.ProvisionAutomationRef }),
+		)).
 		Named("provision").
 		Complete(r)
 }
@@ -21,9 +21,11 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
@@ -113,4 +115,85 @@ var _ = Describe("Provision Controller", func() {
 		Expect(fetched.Status.Phase).To(Equal(
 			isobootgithubiov1alpha1.ProvisionPhaseComplete))
 	})
+
+	It("sets ReferencesResolved once every reference exists", func() {
+		prov := &isobootgithubiov1alpha1.Provision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-references",
+				Namespace: "default",
+			},
+			Spec: isobootgithubiov1alpha1.ProvisionSpec{
+				MachineRef:             "refs-machine",
+				BootConfigRef:          "refs-bootconfig",
+				ProvisionAutomationRef: "refs-automation",
+			},
+		}
+		Expect(k8sClient.Create(ctx, prov)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, prov)).To(Succeed())
+		}()
+
+		reconciler := &ProvisionReconciler{
+			Client: k8sClient,
+			Scheme: scheme.Scheme,
+		}
+		key := types.NamespacedName{Name: "test-references", Namespace: "default"}
+
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).NotTo(BeZero())
+
+		var fetched isobootgithubiov1alpha1.Provision
+		Expect(k8sClient.Get(ctx, key, &fetched)).To(Succeed())
+		Expect(fetched.Status.ObservedGeneration).To(Equal(fetched.Generation))
+		resolved := meta.FindStatusCondition(fetched.Status.Conditions,
+			isobootgithubiov1alpha1.ConditionReferencesResolved)
+		Expect(resolved).NotTo(BeNil())
+		Expect(resolved.Status).To(Equal(metav1.ConditionFalse))
+		Expect(resolved.Reason).To(Equal("MachineNotFound"))
+		Expect(meta.IsStatusConditionTrue(fetched.Status.Conditions,
+			isobootgithubiov1alpha1.ConditionProgressing)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(fetched.Status.Conditions,
+			isobootgithubiov1alpha1.ConditionReady)).To(BeTrue())
+
+		for _, obj := range []client.Object{
+			&isobootgithubiov1alpha1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "refs-machine", Namespace: "default"},
+				Spec:       isobootgithubiov1alpha1.MachineSpec{MAC: "aa-bb-cc-dd-ee-01"},
+			},
+			&isobootgithubiov1alpha1.BootConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "refs-bootconfig", Namespace: "default"},
+				Spec: isobootgithubiov1alpha1.BootConfigSpec{
+					Netboot: &isobootgithubiov1alpha1.BootConfigNetbootSpec{KernelRef: "k", InitrdRef: "i"},
+				},
+			},
+			&isobootgithubiov1alpha1.ProvisionAutomation{
+				ObjectMeta: metav1.ObjectMeta{Name: "refs-automation", Namespace: "default"},
+				Spec:       isobootgithubiov1alpha1.ProvisionAutomationSpec{Files: map[string]string{"preseed.cfg": "d-i"}},
+			},
+		} {
+			Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+			}()
+		}
+
+		result, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(k8sClient.Get(ctx, key, &fetched)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(fetched.Status.Conditions,
+			isobootgithubiov1alpha1.ConditionReferencesResolved)).To(BeTrue())
+
+		// Completing the provision makes it Ready.
+		fetched.Status.Phase = isobootgithubiov1alpha1.ProvisionPhaseComplete
+		Expect(k8sClient.Status().Update(ctx, &fetched)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, &fetched)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(fetched.Status.Conditions,
+			isobootgithubiov1alpha1.ConditionReady)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(fetched.Status.Conditions,
+			isobootgithubiov1alpha1.ConditionProgressing)).To(BeTrue())
+	})
 })
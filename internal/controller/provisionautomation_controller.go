@@ -0,0 +1,88 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// ProvisionAutomationReconciler reconciles a ProvisionAutomation object
+type ProvisionAutomationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=isoboot.github.io,resources=provisionautomations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=isoboot.github.io,resources=provisionautomations/status,verbs=get;update;patch
+
+func (r *ProvisionAutomationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var pa isobootgithubiov1alpha1.ProvisionAutomation
+	if err := r.Get(ctx, req.NamespacedName, &pa); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	ready, reason, message := true, reasonValid, ""
+	if err := parseTemplates(pa.Spec.Files); err != nil {
+		ready, reason, message = false, reasonInvalidTemplate, err.Error()
+	}
+
+	changed := pa.Status.ObservedGeneration != pa.Generation
+	pa.Status.ObservedGeneration = pa.Generation
+	if setCondition(&pa.Status.Conditions, pa.Generation,
+		isobootgithubiov1alpha1.ConditionReady, ready, reason, message) {
+		changed = true
+	}
+	if changed {
+		if err := r.Status().Update(ctx, &pa); err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// parseTemplates parses each file the way the HTTP server does before
+// rendering it, and returns the first error in file name order.
+func parseTemplates(files map[string]string) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if _, err := template.New(name).Option("missingkey=error").Parse(files[name]); err != nil {
+			return fmt.Errorf("parsing template %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProvisionAutomationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&isobootgithubiov1alpha1.ProvisionAutomation{}).
+		Named("provisionautomation").
+		Complete(r)
+}
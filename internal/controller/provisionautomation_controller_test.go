@@ -0,0 +1,61 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+var _ = Describe("ProvisionAutomation Controller", func() {
+	ctx := context.Background()
+
+	DescribeTable("sets Ready from whether the templates parse",
+		func(name string, files map[string]string, ready bool, reason string) {
+			pa := &isobootgithubiov1alpha1.ProvisionAutomation{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       isobootgithubiov1alpha1.ProvisionAutomationSpec{Files: files},
+			}
+			Expect(k8sClient.Create(ctx, pa)).To(Succeed())
+			defer func() { Expect(k8sClient.Delete(ctx, pa)).To(Succeed()) }()
+
+			reconciler := &ProvisionAutomationReconciler{Client: k8sClient, Scheme: scheme.Scheme}
+			key := types.NamespacedName{Name: name, Namespace: "default"}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			var fetched isobootgithubiov1alpha1.ProvisionAutomation
+			Expect(k8sClient.Get(ctx, key, &fetched)).To(Succeed())
+			Expect(fetched.Status.ObservedGeneration).To(Equal(fetched.Generation))
+			condition := meta.FindStatusCondition(fetched.Status.Conditions, isobootgithubiov1alpha1.ConditionReady)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status == metav1.ConditionTrue).To(Equal(ready))
+			Expect(condition.Reason).To(Equal(reason))
+		},
+		Entry("valid", "pa-valid", map[string]string{"preseed.cfg": "{{ .ProvisionName }}"}, true, "Valid"),
+		Entry("unclosed action", "pa-invalid", map[string]string{"preseed.cfg": "{{ .ProvisionName "}, false, "InvalidTemplate"),
+	)
+})
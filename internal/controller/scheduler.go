@@ -0,0 +1,205 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// queuedEntryTTL drops a waiting artifact that has not asked for a slot in
+// this long (deleted, or no longer needing a download), so it cannot hold
+// back the rest of the queue.
+const queuedEntryTTL = 5 * time.Minute
+
+// DownloadScheduler is the controller-wide download queue. It caps the number
+// of BootArtifact downloads running at once and, optionally, their combined
+// bandwidth. Artifacts that cannot start yet wait in order of spec.priority
+// and then arrival, and are re-queued through Wakeups when a slot frees up.
+// A nil *DownloadScheduler imposes no limits.
+type DownloadScheduler struct {
+	maxConcurrent int
+	limiter       *rate.Limiter
+	wakeups       chan event.GenericEvent
+
+	mu      sync.Mutex
+	active  map[types.NamespacedName]struct{}
+	waiting map[types.NamespacedName]*queuedDownload
+	seq     uint64
+}
+
+type queuedDownload struct {
+	key      types.NamespacedName
+	priority int32
+	seq      uint64
+	lastSeen time.Time
+}
+
+// NewDownloadScheduler returns a scheduler that allows maxConcurrent
+// downloads at once and shares bytesPerSecond of bandwidth between them.
+// Zero disables either limit.
+func NewDownloadScheduler(maxConcurrent int, bytesPerSecond int64) *DownloadScheduler {
+	s := &DownloadScheduler{
+		maxConcurrent: maxConcurrent,
+		wakeups:       make(chan event.GenericEvent, 1024),
+		active:        map[types.NamespacedName]struct{}{},
+		waiting:       map[types.NamespacedName]*queuedDownload{},
+	}
+	if bytesPerSecond > 0 {
+		burst := int(min(bytesPerSecond, 256<<10))
+		s.limiter = rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+	}
+	return s
+}
+
+// TryAcquire reserves a download slot for key. When no slot is free, or
+// artifacts queued ahead of key would take the free ones, key is queued (or
+// its priority refreshed) and TryAcquire returns false with key's 1-based
+// position in the queue.
+func (s *DownloadScheduler) TryAcquire(key types.NamespacedName, priority int32) (bool, int) {
+	if s == nil {
+		return true, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.active[key]; ok {
+		return true, 0
+	}
+	now := time.Now()
+	for k, w := range s.waiting {
+		if now.Sub(w.lastSeen) > queuedEntryTTL {
+			delete(s.waiting, k)
+		}
+	}
+	w, ok := s.waiting[key]
+	if !ok {
+		s.seq++
+		w = &queuedDownload{key: key, seq: s.seq}
+		s.waiting[key] = w
+	}
+	w.priority = priority
+	w.lastSeen = now
+
+	position := 1
+	for _, other := range s.waiting {
+		if compareQueued(other, w) < 0 {
+			position++
+		}
+	}
+	if s.maxConcurrent <= 0 || position <= s.maxConcurrent-len(s.active) {
+		delete(s.waiting, key)
+		s.active[key] = struct{}{}
+		return true, 0
+	}
+	return false, position
+}
+
+// Release frees key's download slot and wakes the artifacts now at the head
+// of the queue.
+func (s *DownloadScheduler) Release(key types.NamespacedName) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, key)
+
+	queue := make([]*queuedDownload, 0, len(s.waiting))
+	for _, w := range s.waiting {
+		queue = append(queue, w)
+	}
+	slices.SortFunc(queue, compareQueued)
+	free := len(queue)
+	if s.maxConcurrent > 0 {
+		free = min(free, max(s.maxConcurrent-len(s.active), 0))
+	}
+	for _, w := range queue[:free] {
+		s.wake(w.key)
+	}
+}
+
+// Forget removes key from the queue, e.g. once its BootArtifact is deleted.
+func (s *DownloadScheduler) Forget(key types.NamespacedName) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.waiting, key)
+}
+
+// Wakeups delivers an event for each queued artifact that should retry now.
+func (s *DownloadScheduler) Wakeups() <-chan event.GenericEvent {
+	return s.wakeups
+}
+
+// Reader wraps r so that reads draw from the shared bandwidth budget.
+func (s *DownloadScheduler) Reader(ctx context.Context, r io.Reader) io.Reader {
+	if s == nil || s.limiter == nil {
+		return r
+	}
+	return &rateLimitedReader{ctx: ctx, r: r, limiter: s.limiter}
+}
+
+// wake sends a non-blocking event for key; if the channel is full the
+// artifact still retries on its periodic requeue.
+func (s *DownloadScheduler) wake(key types.NamespacedName) {
+	obj := &isobootgithubiov1alpha1.BootArtifact{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+	}
+	select {
+	case s.wakeups <- event.GenericEvent{Object: obj}:
+	default:
+	}
+}
+
+// compareQueued orders the queue: higher priority first, then first come
+// first served.
+func compareQueued(a, b *queuedDownload) int {
+	return cmp.Or(cmp.Compare(b.priority, a.priority), cmp.Compare(a.seq, b.seq))
+}
+
+type rateLimitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+func (l *rateLimitedReader) Read(p []byte) (int, error) {
+	if burst := l.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := l.r.Read(p)
+	if n > 0 {
+		if waitErr := l.limiter.WaitN(l.ctx, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
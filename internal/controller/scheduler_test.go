@@ -0,0 +1,118 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("DownloadScheduler", func() {
+	key := func(name string) types.NamespacedName {
+		return types.NamespacedName{Name: name, Namespace: "default"}
+	}
+
+	It("limits concurrent downloads", func() {
+		s := NewDownloadScheduler(2, 0)
+		Expect(s.TryAcquire(key("a"), 0)).To(BeTrue())
+		Expect(s.TryAcquire(key("b"), 0)).To(BeTrue())
+
+		ok, position := s.TryAcquire(key("c"), 0)
+		Expect(ok).To(BeFalse())
+		Expect(position).To(Equal(1))
+
+		// Asking again while holding a slot does not take a second one.
+		ok, _ = s.TryAcquire(key("a"), 0)
+		Expect(ok).To(BeTrue())
+
+		s.Release(key("a"))
+		ok, _ = s.TryAcquire(key("c"), 0)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("starts queued downloads by priority, then arrival", func() {
+		s := NewDownloadScheduler(1, 0)
+		Expect(s.TryAcquire(key("running"), 0)).To(BeTrue())
+
+		ok, position := s.TryAcquire(key("first"), 0)
+		Expect(ok).To(BeFalse())
+		Expect(position).To(Equal(1))
+		_, position = s.TryAcquire(key("second"), 0)
+		Expect(position).To(Equal(2))
+		_, position = s.TryAcquire(key("urgent"), 10)
+		Expect(position).To(Equal(1))
+		_, position = s.TryAcquire(key("first"), 0)
+		Expect(position).To(Equal(2))
+
+		s.Release(key("running"))
+		var wakeup event.GenericEvent
+		Expect(s.Wakeups()).To(Receive(&wakeup))
+		Expect(wakeup.Object.GetName()).To(Equal("urgent"))
+		Expect(s.Wakeups()).NotTo(Receive())
+
+		// A queued artifact cannot jump ahead of the one that was woken.
+		ok, _ = s.TryAcquire(key("first"), 0)
+		Expect(ok).To(BeFalse())
+		ok, _ = s.TryAcquire(key("urgent"), 10)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("drops forgotten artifacts from the queue", func() {
+		s := NewDownloadScheduler(1, 0)
+		Expect(s.TryAcquire(key("running"), 0)).To(BeTrue())
+		_, _ = s.TryAcquire(key("deleted"), 0)
+		_, position := s.TryAcquire(key("next"), 0)
+		Expect(position).To(Equal(2))
+
+		s.Forget(key("deleted"))
+		_, position = s.TryAcquire(key("next"), 0)
+		Expect(position).To(Equal(1))
+	})
+
+	It("imposes no limits when nil or unlimited", func() {
+		var s *DownloadScheduler
+		Expect(s.TryAcquire(key("a"), 0)).To(BeTrue())
+		s.Release(key("a"))
+		s.Forget(key("a"))
+		r := bytes.NewReader(nil)
+		Expect(s.Reader(context.Background(), r)).To(BeIdenticalTo(r))
+
+		s = NewDownloadScheduler(0, 0)
+		for _, name := range []string{"a", "b", "c", "d"} {
+			Expect(s.TryAcquire(key(name), 0)).To(BeTrue())
+		}
+	})
+
+	It("limits the combined download rate", func() {
+		s := NewDownloadScheduler(0, 64<<10)
+		data := make([]byte, 192<<10)
+
+		start := time.Now()
+		n, err := io.Copy(io.Discard, s.Reader(context.Background(), bytes.NewReader(data)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(len(data))))
+		// The first 64 KiB burst is free; the rest takes about two seconds.
+		Expect(time.Since(start)).To(BeNumerically(">", time.Second))
+	})
+})
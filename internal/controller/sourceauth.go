@@ -0,0 +1,215 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// tokenKey is the Secret key holding a bearer token for spec.auth.
+const tokenKey = "token"
+
+// sourceClients caches the HTTP clients built for spec.auth, so connections
+// (and TLS sessions) are reused across requests and reconciles. An entry is
+// rebuilt when its fingerprint, covering the auth spec and the
+// resourceVersions of the Secret and ConfigMap, changes.
+type sourceClients struct {
+	mu      sync.Mutex
+	clients map[types.NamespacedName]cachedSourceClient
+}
+
+type cachedSourceClient struct {
+	fingerprint string
+	client      *http.Client
+}
+
+// sourceClient returns the HTTP client for the artifact's download sources:
+// r.HTTPClient, or a client configured from spec.auth.
+func (r *BootArtifactReconciler) sourceClient(ctx context.Context, artifact *isobootgithubiov1alpha1.BootArtifact) (*http.Client, error) {
+	auth := artifact.Spec.Auth
+	if auth == nil || (auth.SecretRef == nil && auth.CABundle == nil) {
+		return r.HTTPClient, nil
+	}
+
+	var secret corev1.Secret
+	var caBundle corev1.ConfigMap
+	fingerprint := fmt.Sprintf("%v", sourceHosts(artifact))
+	if auth.SecretRef != nil {
+		if err := r.Get(ctx, client.ObjectKey{Namespace: artifact.Namespace, Name: auth.SecretRef.Name}, &secret); err != nil {
+			return nil, fmt.Errorf("getting auth Secret %q: %w", auth.SecretRef.Name, err)
+		}
+		fingerprint += fmt.Sprintf("/secret=%s@%s", secret.Name, secret.ResourceVersion)
+	}
+	if auth.CABundle != nil {
+		if err := r.Get(ctx, client.ObjectKey{Namespace: artifact.Namespace, Name: auth.CABundle.Name}, &caBundle); err != nil {
+			return nil, fmt.Errorf("getting CA bundle ConfigMap %q: %w", auth.CABundle.Name, err)
+		}
+		fingerprint += fmt.Sprintf("/ca=%s/%s@%s", caBundle.Name, auth.CABundle.Key, caBundle.ResourceVersion)
+	}
+
+	key := client.ObjectKeyFromObject(artifact)
+	r.sourceClients.mu.Lock()
+	defer r.sourceClients.mu.Unlock()
+	if cached, ok := r.sourceClients.clients[key]; ok {
+		if cached.fingerprint == fingerprint {
+			return cached.client, nil
+		}
+		cached.client.CloseIdleConnections()
+	}
+
+	hc, err := r.newSourceClient(auth, &secret, &caBundle, sourceHosts(artifact))
+	if err != nil {
+		delete(r.sourceClients.clients, key)
+		return nil, err
+	}
+	if r.sourceClients.clients == nil {
+		r.sourceClients.clients = map[types.NamespacedName]cachedSourceClient{}
+	}
+	r.sourceClients.clients[key] = cachedSourceClient{fingerprint: fingerprint, client: hc}
+	return hc, nil
+}
+
+// forgetSourceClient drops the cached client for a deleted artifact.
+func (r *BootArtifactReconciler) forgetSourceClient(key types.NamespacedName) {
+	r.sourceClients.mu.Lock()
+	defer r.sourceClients.mu.Unlock()
+	if cached, ok := r.sourceClients.clients[key]; ok {
+		cached.client.CloseIdleConnections()
+		delete(r.sourceClients.clients, key)
+	}
+}
+
+// newSourceClient builds a client from r.HTTPClient with the TLS settings and
+// credentials of spec.auth.
+func (r *BootArtifactReconciler) newSourceClient(auth *isobootgithubiov1alpha1.BootArtifactAuth, secret *corev1.Secret, caBundle *corev1.ConfigMap, hosts []string) (*http.Client, error) {
+	base, ok := r.HTTPClient.Transport.(*http.Transport)
+	if !ok || base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	transport := base.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if auth.CABundle != nil {
+		pem, ok := caBundle.Data[auth.CABundle.Key]
+		if !ok {
+			return nil, fmt.Errorf("CA bundle ConfigMap %q has no key %q", auth.CABundle.Name, auth.CABundle.Key)
+		}
+		pool := transport.TLSClientConfig.RootCAs
+		if pool != nil {
+			pool = pool.Clone()
+		} else if pool, _ = x509.SystemCertPool(); pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(pem)) {
+			return nil, fmt.Errorf("CA bundle ConfigMap %q key %q contains no PEM certificates", auth.CABundle.Name, auth.CABundle.Key)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	header := http.Header{}
+	if auth.SecretRef != nil {
+		certPEM, hasCert := secret.Data[corev1.TLSCertKey]
+		keyPEM, hasKey := secret.Data[corev1.TLSPrivateKeyKey]
+		if hasCert || hasKey {
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("loading client certificate from Secret %q: %w", auth.SecretRef.Name, err)
+			}
+			transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		token, hasToken := secret.Data[tokenKey]
+		username, hasUsername := secret.Data[corev1.BasicAuthUsernameKey]
+		switch {
+		case hasToken && hasUsername:
+			return nil, fmt.Errorf("auth Secret %q sets both %q and %q", auth.SecretRef.Name, tokenKey, corev1.BasicAuthUsernameKey)
+		case hasToken:
+			header.Set("Authorization", "Bearer "+string(token))
+		case hasUsername:
+			credentials := string(username) + ":" + string(secret.Data[corev1.BasicAuthPasswordKey])
+			header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+		}
+	}
+
+	return &http.Client{
+		Transport:     &sourceAuthTransport{base: transport, header: header, hosts: hosts},
+		CheckRedirect: r.HTTPClient.CheckRedirect,
+		Timeout:       r.HTTPClient.Timeout,
+	}, nil
+}
+
+// sourceHosts lists the hosts of the artifact's HTTPS sources, the only hosts
+// that spec.auth credentials are sent to.
+func sourceHosts(artifact *isobootgithubiov1alpha1.BootArtifact) []string {
+	urls := append([]string{artifact.Spec.URL}, artifact.Spec.Mirrors...)
+	if cs := artifact.Spec.Checksums; cs != nil {
+		urls = append(urls, cs.URL, cs.SignatureURL)
+	}
+	var hosts []string
+	for _, raw := range urls {
+		if u, err := url.Parse(raw); err == nil && u.Scheme == "https" && !slices.Contains(hosts, u.Host) {
+			hosts = append(hosts, u.Host)
+		}
+	}
+	slices.Sort(hosts)
+	return hosts
+}
+
+// sourceAuthTransport adds the spec.auth headers to requests for the
+// artifact's own source hosts. Requests that already carry an Authorization
+// header, such as OCI registry requests, are left alone.
+type sourceAuthTransport struct {
+	base   http.RoundTripper
+	header http.Header
+	hosts  []string
+}
+
+func (t *sourceAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.header) == 0 || req.URL.Scheme != "https" || !slices.Contains(t.hosts, req.URL.Host) ||
+		req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	for k, v := range t.header {
+		req.Header[k] = v
+	}
+	return t.base.RoundTrip(req)
+}
+
+// CloseIdleConnections lets http.Client.CloseIdleConnections reach the
+// underlying transport.
+func (t *sourceAuthTransport) CloseIdleConnections() {
+	if c, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
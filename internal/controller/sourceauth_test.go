@@ -0,0 +1,277 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// testPKI is a throwaway CA that issues server and client certificates.
+type testPKI struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	serial  int64
+}
+
+func newTestPKI() (*testPKI, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "isoboot test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &testPKI{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		serial:  1,
+	}, nil
+}
+
+// issue returns a certificate and key, both PEM-encoded, for a server on
+// 127.0.0.1 or for a client.
+func (p *testPKI) issue(server bool) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(p.serial),
+		Subject:      pkix.Name{CommonName: "isoboot test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if server {
+		tmpl.Subject.CommonName = "127.0.0.1"
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, p.cert, &key.PublicKey, p.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+var _ = Describe("BootArtifact Controller source auth", func() {
+	var (
+		ctx        context.Context
+		dataDir    string
+		reconciler *BootArtifactReconciler
+		pki        *testPKI
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dataDir, err = os.MkdirTemp("", "isoboot-auth-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		// The default client trusts only the system roots, not the test CA.
+		reconciler = &BootArtifactReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), DataDir: dataDir, HTTPClient: &http.Client{}}
+		pki, err = newTestPKI()
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() { Expect(os.RemoveAll(dataDir)).To(Succeed()) })
+
+	doReconcile := func(name string) (reconcile.Result, error) {
+		return reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+		})
+	}
+	getStatus := func(name string) isobootgithubiov1alpha1.BootArtifactStatus {
+		var a isobootgithubiov1alpha1.BootArtifact
+		ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &a)).To(Succeed())
+		return a.Status
+	}
+
+	// startServer serves handler over TLS with a certificate from the test
+	// CA, optionally requiring a client certificate from it as well.
+	startServer := func(handler http.HandlerFunc, requireClientCert bool) *httptest.Server {
+		certPEM, keyPEM, err := pki.issue(true)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		server := httptest.NewUnstartedServer(handler)
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		if requireClientCert {
+			pool := x509.NewCertPool()
+			pool.AddCert(pki.cert)
+			server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+			server.TLS.ClientCAs = pool
+		}
+		server.StartTLS()
+		return server
+	}
+
+	createObject := func(obj client.Object) func() {
+		ExpectWithOffset(1, k8sClient.Create(ctx, obj)).To(Succeed())
+		return func() { _ = k8sClient.Delete(ctx, obj) }
+	}
+
+	makeCABundle := func(name string) func() {
+		return createObject(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string]string{"ca.crt": string(pki.certPEM)},
+		})
+	}
+
+	makeArtifact := func(name, url string, content []byte, auth *isobootgithubiov1alpha1.BootArtifactAuth) func() {
+		a := &isobootgithubiov1alpha1.BootArtifact{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       isobootgithubiov1alpha1.BootArtifactSpec{URL: url, SHA256: new(sha256Hex(content)), Auth: auth},
+		}
+		ExpectWithOffset(1, k8sClient.Create(ctx, a)).To(Succeed())
+		return func() {
+			_ = k8sClient.Delete(ctx, a)
+			// Run the finalizer so the object is actually removed.
+			_, _ = doReconcile(name)
+		}
+	}
+
+	It("downloads with a bearer token, client certificate and CA bundle", func() {
+		content := []byte("private kernel")
+		server := startServer(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write(content)
+		}, true)
+		defer server.Close()
+
+		clientCert, clientKey, err := pki.issue(false)
+		Expect(err).NotTo(HaveOccurred())
+		defer createObject(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "auth-mtls-creds", Namespace: "default"},
+			Data: map[string][]byte{
+				"token":                 []byte("s3cret"),
+				corev1.TLSCertKey:       clientCert,
+				corev1.TLSPrivateKeyKey: clientKey,
+			},
+		})()
+		defer makeCABundle("auth-mtls-ca")()
+		defer makeArtifact("auth-mtls", server.URL+"/vmlinuz", content, &isobootgithubiov1alpha1.BootArtifactAuth{
+			SecretRef: &isobootgithubiov1alpha1.SecretReference{Name: "auth-mtls-creds"},
+			CABundle:  &isobootgithubiov1alpha1.ConfigMapKeyReference{Name: "auth-mtls-ca", Key: "ca.crt"},
+		})()
+
+		_, err = doReconcile("auth-mtls")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getStatus("auth-mtls").Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		data, err := os.ReadFile(filepath.Join(dataDir, "artifacts", "auth-mtls", "vmlinuz"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(content))
+	})
+
+	It("does not send credentials to redirect targets", func() {
+		content := []byte("redirected kernel")
+		var cdnAuthorization atomic.Pointer[string]
+		cdn := startServer(func(w http.ResponseWriter, r *http.Request) {
+			cdnAuthorization.Store(new(r.Header.Get("Authorization")))
+			_, _ = w.Write(content)
+		}, false)
+		defer cdn.Close()
+		origin := startServer(func(w http.ResponseWriter, r *http.Request) {
+			if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.Redirect(w, r, cdn.URL+"/vmlinuz", http.StatusFound)
+		}, false)
+		defer origin.Close()
+
+		defer createObject(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "auth-basic-creds", Namespace: "default"},
+			Data: map[string][]byte{
+				corev1.BasicAuthUsernameKey: []byte("user"),
+				corev1.BasicAuthPasswordKey: []byte("pass"),
+			},
+		})()
+		defer makeCABundle("auth-basic-ca")()
+		defer makeArtifact("auth-basic", origin.URL+"/vmlinuz", content, &isobootgithubiov1alpha1.BootArtifactAuth{
+			SecretRef: &isobootgithubiov1alpha1.SecretReference{Name: "auth-basic-creds"},
+			CABundle:  &isobootgithubiov1alpha1.ConfigMapKeyReference{Name: "auth-basic-ca", Key: "ca.crt"},
+		})()
+
+		_, err := doReconcile("auth-basic")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getStatus("auth-basic").Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseReady))
+		Expect(cdnAuthorization.Load()).To(HaveValue(BeEmpty()))
+	})
+
+	It("fails when the auth Secret is missing", func() {
+		defer makeArtifact("auth-missing", "https://127.0.0.1:1/vmlinuz", []byte("x"), &isobootgithubiov1alpha1.BootArtifactAuth{
+			SecretRef: &isobootgithubiov1alpha1.SecretReference{Name: "no-such-secret"},
+		})()
+
+		result, err := doReconcile("auth-missing")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).NotTo(BeZero())
+		status := getStatus("auth-missing")
+		Expect(status.Phase).To(Equal(isobootgithubiov1alpha1.BootArtifactPhaseError))
+		Expect(status.Message).To(ContainSubstring("no-such-secret"))
+	})
+})
@@ -0,0 +1,78 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	isobootgithubiov1alpha1 "github.com/isoboot/isoboot/api/v1alpha1"
+)
+
+// Files extracted or concatenated into a boot directory are current while
+// the artifacts they came from keep the same digests. Modification times
+// cannot tell: an artifact switched to a blob already in the store is
+// hardlinked to it and keeps the blob's original mtime, which may predate
+// files assembled from the artifact's previous contents.
+
+// sourceStampFile records the digests of the artifacts a boot directory
+// was assembled from.
+const sourceStampFile = ".sources"
+
+// artifactDigest returns the digest of the file served for a Ready
+// artifact, in "<algorithm>:<hex>" form.
+func artifactDigest(artifact *isobootgithubiov1alpha1.BootArtifact) string {
+	switch {
+	case artifact.Spec.Decompress != "":
+		return artifact.Status.DecompressedDigest
+	case artifact.Spec.SHA256 != nil:
+		return digest{algorithm: "sha256", hex: *artifact.Spec.SHA256}.String()
+	case artifact.Spec.SHA512 != nil:
+		return digest{algorithm: "sha512", hex: *artifact.Spec.SHA512}.String()
+	}
+	return artifact.Status.ResolvedDigest
+}
+
+// sourceStamp returns the stamp of a boot directory assembled from
+// artifacts, in order.
+func sourceStamp(artifacts ...*isobootgithubiov1alpha1.BootArtifact) string {
+	digests := make([]string, 0, len(artifacts))
+	for _, a := range artifacts {
+		digests = append(digests, artifactDigest(a))
+	}
+	return strings.Join(digests, "\n") + "\n"
+}
+
+// stampCurrent reports whether the files in bootDir were assembled from
+// the artifacts stamp describes.
+func stampCurrent(bootDir, stamp string) bool {
+	data, err := os.ReadFile(filepath.Join(bootDir, sourceStampFile))
+	return err == nil && string(data) == stamp
+}
+
+// writeStamp records that the files in bootDir were assembled from the
+// artifacts stamp describes.
+func writeStamp(bootDir, stamp string) error {
+	return os.WriteFile(filepath.Join(bootDir, sourceStampFile), []byte(stamp), 0o644)
+}
+
+// extracted reports whether the file at dst exists.
+func extracted(dst string) bool {
+	_, err := os.Stat(dst)
+	return err == nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// verifiedSuffix names the sidecar file that records the last successful
+// verification of an artifact file, e.g. artifacts/<name>/vmlinuz.verified.
+const verifiedSuffix = ".verified"
+
+// verificationRecord ties a digest to the exact file it was computed from.
+// If the file is replaced or rewritten its inode, size or mtime changes and
+// the record no longer matches, so the file is hashed again. Corruption that
+// leaves all of these intact is caught by periodic verification, which
+// always rehashes.
+type verificationRecord struct {
+	Device    uint64 `json:"device"`
+	Inode     uint64 `json:"inode"`
+	Size      int64  `json:"size"`
+	ModTimeNs int64  `json:"mtimeNs"`
+	Algorithm string `json:"algorithm"`
+	Digest    string `json:"digest"`
+}
+
+// statRecord returns the file identity fields of a verificationRecord for path.
+func statRecord(path string) (verificationRecord, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return verificationRecord{}, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return verificationRecord{}, fmt.Errorf("no inode information for %s", path)
+	}
+	return verificationRecord{
+		Device:    uint64(st.Dev), //nolint:unconvert // Dev is not uint64 on every platform
+		Inode:     st.Ino,
+		Size:      info.Size(),
+		ModTimeNs: info.ModTime().UnixNano(),
+	}, nil
+}
+
+// hasVerifiedRecord reports whether path was already verified against want
+// and has not changed since.
+func hasVerifiedRecord(path string, want digest) bool {
+	data, err := os.ReadFile(path + verifiedSuffix)
+	if err != nil {
+		return false
+	}
+	var recorded verificationRecord
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return false
+	}
+	current, err := statRecord(path)
+	if err != nil {
+		return false
+	}
+	return recorded.Algorithm == want.algorithm && strings.EqualFold(recorded.Digest, want.hex) &&
+		recorded.Device == current.Device && recorded.Inode == current.Inode &&
+		recorded.Size == current.Size && recorded.ModTimeNs == current.ModTimeNs
+}
+
+// recordVerified writes the verification record for path after its digest
+// has been checked against want. Failures only cost a rehash later, so they
+// are logged rather than returned.
+func recordVerified(ctx context.Context, path string, want digest) {
+	log := logf.FromContext(ctx)
+	record, err := statRecord(path)
+	if err != nil {
+		log.Error(err, "Failed to record verification", "path", path)
+		return
+	}
+	record.Algorithm = want.algorithm
+	record.Digest = strings.ToLower(want.hex)
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Error(err, "Failed to record verification", "path", path)
+		return
+	}
+	tmp := path + verifiedSuffix + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		log.Error(err, "Failed to record verification", "path", path)
+		return
+	}
+	if err := os.Rename(tmp, path+verifiedSuffix); err != nil {
+		_ = os.Remove(tmp)
+		log.Error(err, "Failed to record verification", "path", path)
+	}
+}
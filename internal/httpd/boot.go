@@ -37,6 +37,9 @@ type BootDirective struct {
 	InitrdPath    string
 	ISOPath       string
 	ProvisionName string
+	// Files maps the name of each file extracted from an ISO, archive or
+	// disk image besides the kernel and initrd to its path, like KernelPath.
+	Files map[string]string
 }
 
 // KernelArgsData holds the template data for kernel args rendering.
@@ -46,6 +49,9 @@ type KernelArgsData struct {
 	UpdatePhaseURL             string
 	ProvisionName              string
 	ISOURL                     string
+	// Files maps the name of each extra file extracted from an ISO, archive
+	// or disk image to its URL, for use as {{index .Files "images/install.img"}}.
+	Files map[string]string
 }
 
 // RenderKernelArgs renders kernel args as a Go template with the given data.
@@ -92,7 +98,8 @@ func BootDirectiveForMAC(
 			provision.Spec.BootConfigRef, err)
 	}
 
-	// Mode B (ISO): kernel and initrd are extracted to fixed filenames.
+	// Mode B (ISO): kernel and initrd are extracted to fixed filenames, the
+	// initrd followed by any initrd overlay under overlaid/.
 	if bc.Spec.ISO != nil {
 		var isoArtifact isobootgithubiov1alpha1.BootArtifact
 		if err := c.Get(ctx, client.ObjectKey{
@@ -102,19 +109,45 @@ func BootDirectiveForMAC(
 			return nil, fmt.Errorf("getting iso artifact %q: %w",
 				bc.Spec.ISO.ArtifactRef, err)
 		}
-		isoFile := urlutil.FilenameFromURL(isoArtifact.Spec.URL)
+		isoFile := urlutil.ArtifactFilename(isoArtifact.Spec.URL, string(isoArtifact.Spec.Decompress))
+		// Without spec.kernelArgs, boot with the arguments of the ISO's
+		// own default entry, which the controller records without those
+		// bound to the boot media.
+		kernelArgs := bc.Spec.KernelArgs
+		if kernelArgs == "" && bc.Status.ISO != nil {
+			kernelArgs = bc.Status.ISO.KernelArgs
+		}
 		return &BootDirective{
 			KernelPath:    path.Join(bc.Name, "vmlinuz"),
-			KernelArgs:    bc.Spec.KernelArgs,
-			InitrdPath:    path.Join(bc.Name, "initrd"),
+			KernelArgs:    kernelArgs,
+			InitrdPath:    path.Join(bc.Name, bc.ExtractedInitrdPath()),
 			ISOPath:       path.Join(bc.Name, isoFile),
 			ProvisionName: provision.Name,
+			Files:         extraFilePaths(bc.Name, bc.Spec.ISO.ExtraFiles),
+		}, nil
+	}
+
+	// Archive and disk image modes: like ISO mode, without an ISO to serve.
+	var extra []isobootgithubiov1alpha1.BootConfigISOFile
+	switch {
+	case bc.Spec.Archive != nil:
+		extra = bc.Spec.Archive.ExtraFiles
+	case bc.Spec.DiskImage != nil:
+		extra = bc.Spec.DiskImage.ExtraFiles
+	}
+	if bc.Spec.Archive != nil || bc.Spec.DiskImage != nil {
+		return &BootDirective{
+			KernelPath:    path.Join(bc.Name, "vmlinuz"),
+			KernelArgs:    bc.Spec.KernelArgs,
+			InitrdPath:    path.Join(bc.Name, bc.ExtractedInitrdPath()),
+			ProvisionName: provision.Name,
+			Files:         extraFilePaths(bc.Name, extra),
 		}, nil
 	}
 
 	if bc.Spec.Netboot == nil {
 		return nil, fmt.Errorf(
-			"boot config %q has neither netboot nor iso", bc.Name)
+			"boot config %q has none of netboot, iso, archive or diskImage", bc.Name)
 	}
 
 	var kernelArtifact isobootgithubiov1alpha1.BootArtifact
@@ -135,8 +168,8 @@ func BootDirectiveForMAC(
 			bc.Spec.Netboot.InitrdRef, err)
 	}
 
-	kernelFile := urlutil.FilenameFromURL(kernelArtifact.Spec.URL)
-	initrdFile := urlutil.FilenameFromURL(initrdArtifact.Spec.URL)
+	kernelFile := urlutil.ArtifactFilename(kernelArtifact.Spec.URL, string(kernelArtifact.Spec.Decompress))
+	initrdFile := urlutil.ArtifactFilename(initrdArtifact.Spec.URL, string(initrdArtifact.Spec.Decompress))
 
 	return &BootDirective{
 		KernelPath:    path.Join(bc.Name, "kernel", kernelFile),
@@ -145,3 +178,17 @@ func BootDirectiveForMAC(
 		ProvisionName: provision.Name,
 	}, nil
 }
+
+// extraFilePaths maps the name of each extra file extracted into the boot
+// directory bootDir to its path, or returns nil when there are none.
+func extraFilePaths(bootDir string, extra []isobootgithubiov1alpha1.BootConfigISOFile) map[string]string {
+	var files map[string]string
+	for _, f := range extra {
+		if files == nil {
+			files = map[string]string{}
+		}
+		name := f.Name()
+		files[name] = path.Join(bootDir, name)
+	}
+	return files
+}
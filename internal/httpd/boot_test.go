@@ -138,6 +138,10 @@ var _ = Describe("BootDirectiveForMAC", func() {
 					ArtifactRef: "bd-iso-1",
 					KernelPath:  "casper/vmlinuz",
 					InitrdPath:  "casper/initrd",
+					ExtraFiles: []isobootgithubiov1alpha1.BootConfigISOFile{
+						{Path: "casper/minimal.squashfs"},
+						{Path: "/.disk/info", As: "info"},
+					},
 				},
 				KernelArgs: "autoinstall ds=nocloud-net",
 			},
@@ -162,10 +166,154 @@ var _ = Describe("BootDirectiveForMAC", func() {
 		Expect(result.KernelPath).To(Equal("bd-bc3/vmlinuz"))
 		Expect(result.InitrdPath).To(Equal("bd-bc3/initrd"))
 		Expect(result.ISOPath).To(Equal("bd-bc3/ubuntu.iso"))
+		Expect(result.Files).To(Equal(map[string]string{
+			"casper/minimal.squashfs": "bd-bc3/casper/minimal.squashfs",
+			"info":                    "bd-bc3/info",
+		}))
 		Expect(result.KernelArgs).To(Equal("autoinstall ds=nocloud-net"))
 		Expect(result.ProvisionName).To(Equal("bd-p4"))
 	})
 
+	It("falls back to the ISO's own kernel args in ISO mode", func() {
+		m := createMachine("bd-m5", "bb-00-00-00-00-06")
+		ia := createArtifact("bd-iso-2", "https://example.com/rocky.iso")
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "bd-bc4", Namespace: ns},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				ISO: &isobootgithubiov1alpha1.BootConfigISOSpec{ArtifactRef: "bd-iso-2"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, bc)).To(Succeed())
+		bc.Status.ISO = &isobootgithubiov1alpha1.BootConfigISOStatus{
+			KernelPath: "images/pxeboot/vmlinuz", KernelArgs: "inst.text quiet",
+		}
+		Expect(k8sClient.Status().Update(ctx, bc)).To(Succeed())
+		p := createProvision("bd-p5", "bd-m5", "bd-bc4",
+			isobootgithubiov1alpha1.ProvisionPhasePending)
+		defer func() {
+			Expect(k8sClient.Delete(ctx, p)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, bc)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ia)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, m)).To(Succeed())
+		}()
+
+		Eventually(func() string {
+			result, _ := BootDirectiveForMAC(
+				ctx, indexedClient, ns, "bb-00-00-00-00-06")
+			if result == nil {
+				return ""
+			}
+			return result.KernelArgs
+		}).Should(Equal("inst.text quiet"))
+	})
+
+	It("returns archive-mode directive without an ISO", func() {
+		m := createMachine("bd-m6", "bb-00-00-00-00-07")
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "bd-bc5", Namespace: ns},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				Archive: &isobootgithubiov1alpha1.BootConfigArchiveSpec{
+					ArtifactRef: "bd-netboot",
+					KernelPath:  "debian-installer/amd64/linux",
+					InitrdPath:  "debian-installer/amd64/initrd.gz",
+				},
+				KernelArgs: "priority=critical",
+			},
+		}
+		Expect(k8sClient.Create(ctx, bc)).To(Succeed())
+		p := createProvision("bd-p6", "bd-m6", "bd-bc5",
+			isobootgithubiov1alpha1.ProvisionPhasePending)
+		defer func() {
+			Expect(k8sClient.Delete(ctx, p)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, bc)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, m)).To(Succeed())
+		}()
+
+		var result *BootDirective
+		Eventually(func() *BootDirective {
+			result, _ = BootDirectiveForMAC(
+				ctx, indexedClient, ns, "bb-00-00-00-00-07")
+			return result
+		}).ShouldNot(BeNil())
+
+		Expect(result.KernelPath).To(Equal("bd-bc5/vmlinuz"))
+		Expect(result.InitrdPath).To(Equal("bd-bc5/initrd"))
+		Expect(result.ISOPath).To(BeEmpty())
+		Expect(result.KernelArgs).To(Equal("priority=critical"))
+	})
+
+	It("returns disk-image-mode directive with extra files", func() {
+		m := createMachine("bd-m7", "bb-00-00-00-00-08")
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "bd-bc6", Namespace: ns},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				DiskImage: &isobootgithubiov1alpha1.BootConfigDiskImageSpec{
+					ArtifactRef:    "bd-flatcar",
+					PartitionLabel: "EFI-SYSTEM",
+					KernelPath:     "flatcar/vmlinuz-a",
+					InitrdPath:     "flatcar/initrd",
+					ExtraFiles:     []isobootgithubiov1alpha1.BootConfigISOFile{{Path: "EFI/boot/grub.cfg", As: "grub.cfg"}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, bc)).To(Succeed())
+		p := createProvision("bd-p7", "bd-m7", "bd-bc6",
+			isobootgithubiov1alpha1.ProvisionPhasePending)
+		defer func() {
+			Expect(k8sClient.Delete(ctx, p)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, bc)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, m)).To(Succeed())
+		}()
+
+		var result *BootDirective
+		Eventually(func() *BootDirective {
+			result, _ = BootDirectiveForMAC(
+				ctx, indexedClient, ns, "bb-00-00-00-00-08")
+			return result
+		}).ShouldNot(BeNil())
+
+		Expect(result.KernelPath).To(Equal("bd-bc6/vmlinuz"))
+		Expect(result.InitrdPath).To(Equal("bd-bc6/initrd"))
+		Expect(result.ISOPath).To(BeEmpty())
+		Expect(result.Files).To(Equal(map[string]string{"grub.cfg": "bd-bc6/grub.cfg"}))
+	})
+
+	It("returns the overlaid initrd for an extraction mode with initrd overlays", func() {
+		m := createMachine("bd-m8", "bb-00-00-00-00-09")
+		bc := &isobootgithubiov1alpha1.BootConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "bd-bc7", Namespace: ns},
+			Spec: isobootgithubiov1alpha1.BootConfigSpec{
+				Archive: &isobootgithubiov1alpha1.BootConfigArchiveSpec{
+					ArtifactRef: "bd-netboot",
+					KernelPath:  "debian-installer/amd64/linux",
+					InitrdPath:  "debian-installer/amd64/initrd.gz",
+				},
+				InitrdOverlays: []isobootgithubiov1alpha1.BootConfigInitrdOverlay{{
+					Path:            "preseed.cfg",
+					ConfigMapKeyRef: &isobootgithubiov1alpha1.ConfigMapKeyReference{Name: "bd-preseed", Key: "preseed.cfg"},
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, bc)).To(Succeed())
+		p := createProvision("bd-p8", "bd-m8", "bd-bc7",
+			isobootgithubiov1alpha1.ProvisionPhasePending)
+		defer func() {
+			Expect(k8sClient.Delete(ctx, p)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, bc)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, m)).To(Succeed())
+		}()
+
+		var result *BootDirective
+		Eventually(func() *BootDirective {
+			result, _ = BootDirectiveForMAC(
+				ctx, indexedClient, ns, "bb-00-00-00-00-09")
+			return result
+		}).ShouldNot(BeNil())
+
+		Expect(result.KernelPath).To(Equal("bd-bc7/vmlinuz"))
+		Expect(result.InitrdPath).To(Equal("bd-bc7/overlaid/initrd"))
+	})
+
 	It("returns error when boot config not found", func() {
 		m := createMachine("bd-m2", "bb-00-00-00-00-03")
 		p := createProvision("bd-p2", "bd-m2", "nonexistent-bc",
@@ -255,6 +403,16 @@ var _ = Describe("RenderKernelArgs", func() {
 		Expect(result).To(ContainSubstring("inst.provname=my-provision"))
 	})
 
+	It("renders extra file URLs by name", func() {
+		result, err := RenderKernelArgs(
+			`inst.stage2={{index .Files "images/install.img"}}`,
+			KernelArgsData{Files: map[string]string{
+				"images/install.img": "http://10.0.0.1:8080/static/rocky/images/install.img",
+			}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("inst.stage2=http://10.0.0.1:8080/static/rocky/images/install.img"))
+	})
+
 	It("renders ISOURL for ISO-mode autoinstall", func() {
 		result, err := RenderKernelArgs(
 			"ip=dhcp url={{.ISOURL}} autoinstall ds=nocloud-net;s={{.ProvisionAutomationBaseURL}}/",
@@ -19,9 +19,11 @@ package urlutil
 import (
 	"net/url"
 	"path"
+	"strings"
 )
 
-// FilenameFromURL extracts the filename from a URL path.
+// FilenameFromURL extracts the filename from a URL path. For oci:// references
+// it is the last repository path element, without the tag or digest.
 // It returns "artifact" if the URL cannot be parsed or contains no filename.
 func FilenameFromURL(rawURL string) string {
 	u, err := url.Parse(rawURL)
@@ -29,8 +31,33 @@ func FilenameFromURL(rawURL string) string {
 		return "artifact"
 	}
 	name := path.Base(u.Path)
+	if u.Scheme == "oci" {
+		name, _, _ = strings.Cut(name, "@")
+		name, _, _ = strings.Cut(name, ":")
+	}
 	if name == "" || name == "." || name == "/" || name == ".." {
 		return "artifact"
 	}
 	return name
 }
+
+// compressionExtensions maps BootArtifact spec.decompress formats to the file
+// extension dropped from the served filename.
+var compressionExtensions = map[string]string{
+	"gzip": ".gz",
+	"xz":   ".xz",
+	"zstd": ".zst",
+}
+
+// ArtifactFilename returns the name a BootArtifact's file is served under:
+// the filename of rawURL, without the extension of the decompress format
+// when one is given (e.g. "vmlinuz.xz" with "xz" becomes "vmlinuz").
+func ArtifactFilename(rawURL, decompress string) string {
+	name := FilenameFromURL(rawURL)
+	if ext, ok := compressionExtensions[decompress]; ok {
+		if trimmed, ok := strings.CutSuffix(name, ext); ok && trimmed != "" && trimmed != "." && trimmed != ".." {
+			return trimmed
+		}
+	}
+	return name
+}
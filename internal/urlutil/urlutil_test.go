@@ -28,6 +28,9 @@ func TestFilenameFromURL(t *testing.T) {
 		{"nested", "https://example.com/a/b/c/initrd.img", "initrd.img"},
 		{"root", "https://example.com/", "artifact"},
 		{"no path", "https://example.com", "artifact"},
+		{"oci tag", "oci://registry.example.com:5000/debian/vmlinuz:13", "vmlinuz"},
+		{"oci digest", "oci://registry.example.com/debian/initrd.gz@sha256:abcdef", "initrd.gz"},
+		{"oci no tag", "oci://registry.example.com/debian/vmlinuz", "vmlinuz"},
 	}
 
 	for _, tt := range tests {
@@ -39,3 +42,30 @@ func TestFilenameFromURL(t *testing.T) {
 		})
 	}
 }
+
+func TestArtifactFilename(t *testing.T) {
+	tests := []struct {
+		name       string
+		rawURL     string
+		decompress string
+		expected   string
+	}{
+		{"no decompress", "https://example.com/vmlinuz.xz", "", "vmlinuz.xz"},
+		{"xz", "https://example.com/vmlinuz.xz", "xz", "vmlinuz"},
+		{"gzip", "https://example.com/initrd.img.gz", "gzip", "initrd.img"},
+		{"zstd", "https://example.com/disk.raw.zst", "zstd", "disk.raw"},
+		{"other extension", "https://example.com/vmlinuz.gz", "xz", "vmlinuz.gz"},
+		{"no extension", "https://example.com/vmlinuz", "gzip", "vmlinuz"},
+		{"only extension", "https://example.com/.gz", "gzip", ".gz"},
+		{"oci", "oci://registry.example.com/debian/initrd.gz@sha256:abcdef", "gzip", "initrd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ArtifactFilename(tt.rawURL, tt.decompress)
+			if got != tt.expected {
+				t.Errorf("ArtifactFilename(%q, %q) = %q, want %q", tt.rawURL, tt.decompress, got, tt.expected)
+			}
+		})
+	}
+}